@@ -0,0 +1,128 @@
+package ddb
+
+import (
+	"context"
+	"math"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/time/rate"
+)
+
+// AdaptiveThrottleConfig tunes the AIMD controller behind WithAdaptiveThrottle, zero fields fall back to the defaults noted on each one
+type AdaptiveThrottleConfig struct {
+	// InitialWCU is the write rate batch writes start at, defaults to MaxWCU / 4
+	InitialWCU float64
+	// MinWCU is the floor the rate never drops below, defaults to 1
+	MinWCU float64
+	// MaxWCU is the ceiling the rate never grows above, defaults to 1000
+	MaxWCU float64
+	// Increase is the units per second added after every batch that shows no pressure, defaults to 5% of MaxWCU
+	Increase float64
+	// Decrease is the factor the rate is multiplied by when pressure is seen, between 0 and 1, defaults to 0.5
+	Decrease float64
+	// Tolerance is how far above its recent average the capacity consumed per unit written can rise before it counts as pressure, defaults to 0.2 (20%)
+	Tolerance float64
+}
+
+// WithAdaptiveThrottle paces batch writes with an AIMD controller driven by the consumed capacity dynamo reports on every BatchWriteItem response.
+// consumption is normalized by the write units of the items dynamo accepted, so batch size and item size don't read as a trend. the rate grows additively while it stays flat and is cut multiplicatively as soon as it trends upward or items come back unprocessed, so large imports slow down before they get throttled.
+// it works alongside WithWriteRateLimit, a request has to be allowed by both
+func WithAdaptiveThrottle(cfg AdaptiveThrottleConfig) Option {
+	if cfg.MaxWCU <= 0 {
+		cfg.MaxWCU = 1000
+	}
+	if cfg.MinWCU <= 0 {
+		cfg.MinWCU = 1
+	}
+	if cfg.InitialWCU <= 0 {
+		cfg.InitialWCU = cfg.MaxWCU / 4
+	}
+	if cfg.Increase <= 0 {
+		cfg.Increase = cfg.MaxWCU / 20
+	}
+	if cfg.Decrease <= 0 || cfg.Decrease >= 1 {
+		cfg.Decrease = 0.5
+	}
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = 0.2
+	}
+	cfg.InitialWCU = min(max(cfg.InitialWCU, cfg.MinWCU), cfg.MaxWCU)
+
+	return func(d *DynamoDB) {
+		d.adaptive = &adaptiveThrottle{
+			cfg:     cfg,
+			rate:    cfg.InitialWCU,
+			limiter: rate.NewLimiter(rate.Limit(cfg.InitialWCU), max(1, int(math.Ceil(cfg.MaxWCU)))),
+		}
+	}
+}
+
+// adaptiveThrottle is an AIMD rate controller fed with the consumed capacity of batch writes
+type adaptiveThrottle struct {
+	cfg     AdaptiveThrottleConfig
+	limiter *rate.Limiter
+
+	mu   sync.Mutex
+	rate float64
+	// avg is the moving average of the capacity consumed per write unit
+	avg float64
+}
+
+// wait blocks until the current rate allows the provided capacity units
+func (a *adaptiveThrottle) wait(ctx context.Context, units int) error {
+	burst := a.limiter.Burst()
+	for units > 0 {
+		n := min(units, burst)
+		if err := a.limiter.WaitN(ctx, n); err != nil {
+			return err
+		}
+		units -= n
+	}
+	return nil
+}
+
+// observe adjusts the rate after a batch write, cutting it when the capacity consumed per unit written rises above its moving average or items were left unprocessed and growing it otherwise.
+// written is the write units of the requests dynamo processed, a batch with nothing processed leaves the average alone
+func (a *adaptiveThrottle) observe(capacity []types.ConsumedCapacity, written, unprocessed int) {
+	var consumed float64
+	for _, c := range capacity {
+		consumed += aws.ToFloat64(c.CapacityUnits)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var perUnit float64
+	if written > 0 {
+		perUnit = consumed / float64(written)
+	}
+
+	rising := a.avg > 0 && perUnit > a.avg*(1+a.cfg.Tolerance)
+	if rising || unprocessed > 0 {
+		a.rate = max(a.cfg.MinWCU, a.rate*a.cfg.Decrease)
+	} else {
+		a.rate = min(a.cfg.MaxWCU, a.rate+a.cfg.Increase)
+	}
+
+	// exponential moving average so a single batch doesn't define the trend
+	switch {
+	case perUnit == 0:
+	case a.avg == 0:
+		a.avg = perUnit
+	default:
+		a.avg = 0.8*a.avg + 0.2*perUnit
+	}
+
+	a.limiter.SetLimit(rate.Limit(a.rate))
+}
+
+// throttled cuts the rate after dynamo rejected a batch outright for exceeding throughput
+func (a *adaptiveThrottle) throttled() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rate = max(a.cfg.MinWCU, a.rate*a.cfg.Decrease)
+	a.limiter.SetLimit(rate.Limit(a.rate))
+}
@@ -0,0 +1,48 @@
+package ddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func consumed(units float64) []types.ConsumedCapacity {
+	return []types.ConsumedCapacity{{CapacityUnits: aws.Float64(units)}}
+}
+
+func TestAdaptiveThrottleIgnoresBatchShape(t *testing.T) {
+	d := &DynamoDB{}
+	WithAdaptiveThrottle(AdaptiveThrottleConfig{MaxWCU: 100, InitialWCU: 10, Increase: 1})(d)
+	a := d.adaptive
+
+	// full batches, a short final chunk and a switch to items three times larger all cost one unit per unit written
+	for _, written := range []int{25, 25, 25, 3, 75, 75} {
+		before := a.rate
+		a.observe(consumed(float64(written)), written, 0)
+		if a.rate < before {
+			t.Fatalf("expected the rate to keep growing for %d units written at a flat cost, went from %v to %v", written, before, a.rate)
+		}
+	}
+
+	// the same batch size now costing twice as much per unit is pressure
+	before := a.rate
+	a.observe(consumed(150), 75, 0)
+	if a.rate >= before {
+		t.Fatalf("expected the rate to be cut when the cost per unit doubles, went from %v to %v", before, a.rate)
+	}
+}
+
+func TestAdaptiveThrottleCutsOnUnprocessed(t *testing.T) {
+	d := &DynamoDB{}
+	WithAdaptiveThrottle(AdaptiveThrottleConfig{MaxWCU: 100, InitialWCU: 10})(d)
+	a := d.adaptive
+
+	a.observe(nil, 0, 25)
+	if a.rate != 5 {
+		t.Fatalf("expected the rate to be halved, got %v", a.rate)
+	}
+	if a.avg != 0 {
+		t.Fatalf("expected a batch with nothing written to leave the average alone, got %v", a.avg)
+	}
+}
@@ -0,0 +1,73 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxTableCreateWait bounds how long a write waits for an auto created table to become ACTIVE
+const maxTableCreateWait = 5 * time.Minute
+
+type autoCreate struct {
+	mu  sync.Mutex
+	def *dynamodb.CreateTableInput
+}
+
+// WithAutoCreate creates the table from def the first time a write to it fails with ResourceNotFoundException, waits for it to be ACTIVE and retries the write once.
+// the write is only retried once so a table that can't be created surfaces the error instead of looping, meant for local development and ephemeral environments
+func WithAutoCreate(def *dynamodb.CreateTableInput) Option {
+	return func(d *DynamoDB) {
+		d.autoCreate = &autoCreate{def: def}
+	}
+}
+
+// shouldAutoCreate reports whether a failed call is a write to the auto create table that failed because the table doesn't exist
+func (d *DynamoDB) shouldAutoCreate(operation string, tableName *string, err error) bool {
+	if d.autoCreate == nil || err == nil {
+		return false
+	}
+
+	switch operation {
+	case "PutItem", "UpdateItem", "DeleteItem", "BatchWriteItem":
+	default:
+		return false
+	}
+
+	if aws.ToString(tableName) != aws.ToString(d.autoCreate.def.TableName) {
+		return false
+	}
+
+	var rnf *types.ResourceNotFoundException
+	return errors.As(err, &rnf)
+}
+
+// createTable creates the auto create table and waits until it is ACTIVE, concurrent writers share a single creation
+func (d *DynamoDB) createTable(ctx context.Context) error {
+	d.autoCreate.mu.Lock()
+	defer d.autoCreate.mu.Unlock()
+
+	def := d.autoCreate.def
+
+	_, err := call(ctx, d, "CreateTable", def.TableName, def, d.client.CreateTable)
+	if err != nil {
+		// another process or an earlier writer already created it
+		var riu *types.ResourceInUseException
+		if !errors.As(err, &riu) {
+			return err
+		}
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(d.client)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: def.TableName}, maxTableCreateWait); err != nil {
+		return fmt.Errorf("error waiting for table %s: %w", aws.ToString(def.TableName), err)
+	}
+
+	return nil
+}
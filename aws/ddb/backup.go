@@ -0,0 +1,66 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxRestoreWait bounds how long RestoreFromBackup waits for the restored table to become ACTIVE, restores of large tables can take a while
+const maxRestoreWait = time.Hour
+
+// CreateBackup takes an on-demand backup of the table and returns its ARN, a missing table returns ErrTableNotFound
+func (d *DynamoDB) CreateBackup(ctx context.Context, tableName, backupName string) (arn string, err error) {
+	input := &dynamodb.CreateBackupInput{
+		TableName:  aws.String(tableName),
+		BackupName: aws.String(backupName),
+	}
+
+	output, err := call(ctx, d, "CreateBackup", input.TableName, input, d.client.CreateBackup)
+	if err != nil {
+		var tnf *types.TableNotFoundException
+		if errors.As(err, &tnf) {
+			return "", fmt.Errorf("%w: %w", ErrTableNotFound, err)
+		}
+		return "", fmt.Errorf("error creating backup: %w", mapTableErr(err))
+	}
+
+	return aws.ToString(output.BackupDetails.BackupArn), nil
+}
+
+// RestoreFromBackup restores the backup into a new table named targetTable, when wait is set it blocks until the restored table is ACTIVE.
+// a missing backup returns ErrBackupNotFound and an existing target table returns ErrTableAlreadyExists
+func (d *DynamoDB) RestoreFromBackup(ctx context.Context, backupArn, targetTable string, wait bool) error {
+	input := &dynamodb.RestoreTableFromBackupInput{
+		BackupArn:       aws.String(backupArn),
+		TargetTableName: aws.String(targetTable),
+	}
+
+	if _, err := call(ctx, d, "RestoreTableFromBackup", input.TargetTableName, input, d.client.RestoreTableFromBackup); err != nil {
+		var bnf *types.BackupNotFoundException
+		var tae *types.TableAlreadyExistsException
+		switch {
+		case errors.As(err, &bnf):
+			return fmt.Errorf("%w: %w", ErrBackupNotFound, err)
+		case errors.As(err, &tae):
+			return fmt.Errorf("%w: %w", ErrTableAlreadyExists, err)
+		}
+		return fmt.Errorf("error restoring backup: %w", err)
+	}
+
+	if !wait {
+		return nil
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(d.client)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(targetTable)}, maxRestoreWait); err != nil {
+		return fmt.Errorf("error waiting for table %s to be restored: %w", targetTable, err)
+	}
+
+	return nil
+}
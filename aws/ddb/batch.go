@@ -0,0 +1,281 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// maxBatchWriteItems is the maximum number of write requests dynamo accepts in a single BatchWriteItem call
+	maxBatchWriteItems = 25
+	// maxBatchWriteBytes is the maximum payload dynamo accepts in a single BatchWriteItem call
+	maxBatchWriteBytes = 16 << 20
+	// maxBatchRetries is how many times unprocessed items are retried before giving up
+	maxBatchRetries  = 10
+	baseBatchBackoff = 50 * time.Millisecond
+	maxBatchBackoff  = 5 * time.Second
+
+	// defaultBatchWorkers is the worker count of the parallel helpers when neither the call nor WithMaxConcurrency provide one
+	defaultBatchWorkers = 4
+	// maxBatchWorkers caps the parallel helpers so a large worker count can't hammer an under-provisioned table
+	maxBatchWorkers = 16
+)
+
+// BatchWriteItem is a wrapper around dynamodb.BatchWriteItem with an already initialized client
+func (d *DynamoDB) BatchWriteItem(ctx context.Context, input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	if d.dryRun != nil {
+		for tableName, writes := range input.RequestItems {
+			d.recordDryRun("BatchWriteItem", aws.String(tableName), len(writes), input)
+		}
+		return &dynamodb.BatchWriteItemOutput{}, nil
+	}
+	return call(ctx, d, "BatchWriteItem", nil, input, d.client.BatchWriteItem)
+}
+
+// BatchWrite writes all the provided requests to the table in chunks of up to 25 requests and 16MB, retrying unprocessed items with exponential backoff until every write is applied
+func (d *DynamoDB) BatchWrite(ctx context.Context, tableName string, writes []types.WriteRequest) (err error) {
+	ctx, span := d.startSpan(ctx, "BatchWrite", aws.String(tableName))
+	defer func() { endSpan(span, err) }()
+
+	for start, end := 0, 0; start < len(writes); start = end {
+		end = batchWriteEnd(writes, start)
+
+		if err := d.batchWriteChunk(ctx, tableName, writes[start:end]); err != nil {
+			return fmt.Errorf("error writing items %d-%d: %w", start, end-1, err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteMany deletes the items with the provided keys through BatchWrite, returning once every delete is applied. identical keys are only deleted once
+func (d *DynamoDB) DeleteMany(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) error {
+	writes := make([]types.WriteRequest, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if id := keyString(key); !seen[id] {
+			seen[id] = true
+			writes = append(writes, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}})
+		}
+	}
+
+	return d.BatchWrite(ctx, tableName, writes)
+}
+
+// BatchPutStructs marshals every item, adding the WithDerivedKeys attributes, and puts them all through BatchWrite, retrying unprocessed items until every put is applied.
+// items are all marshalled before anything is written, when some fail nothing is written and the error lists the index of every item that couldn't be marshalled
+func BatchPutStructs[T any](ctx context.Context, d *DynamoDB, tableName string, items []T) error {
+	writes := make([]types.WriteRequest, len(items))
+	var errs []error
+	for i, item := range items {
+		av, err := d.marshalMap(item)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error marshalling item %d: %w", i, err))
+			continue
+		}
+		writes[i] = types.WriteRequest{PutRequest: &types.PutRequest{Item: d.withDerivedKeys(av)}}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return d.BatchWrite(ctx, tableName, writes)
+}
+
+// BatchWriteConcurrent is like BatchWrite but dispatches the chunks to a pool of workers, each retrying its own unprocessed items.
+// workers <= 0 uses the wrapper's WithMaxConcurrency, 4 by default, and anything above 16 is capped, the first chunk that fails cancels the remaining workers and its error is returned
+func (d *DynamoDB) BatchWriteConcurrent(ctx context.Context, tableName string, writes []types.WriteRequest, workers int) (err error) {
+	ctx, span := d.startSpan(ctx, "BatchWriteConcurrent", aws.String(tableName))
+	defer func() { endSpan(span, err) }()
+
+	type chunk struct{ start, end int }
+
+	return feedConcurrent(ctx, d.workers(workers), func(_ context.Context, send func(chunk) bool) error {
+		for start, end := 0, 0; start < len(writes); start = end {
+			end = batchWriteEnd(writes, start)
+			if !send(chunk{start: start, end: end}) {
+				break
+			}
+		}
+		return nil
+	}, func(ctx context.Context, c chunk) error {
+		if err := d.batchWriteChunk(ctx, tableName, writes[c.start:c.end]); err != nil {
+			return fmt.Errorf("error writing items %d-%d: %w", c.start, c.end-1, err)
+		}
+		return nil
+	})
+}
+
+// BatchWriteOnce sends the provided requests in chunks of up to 25 requests and 16MB making a single attempt per chunk, it never retries and returns whatever dynamo left unprocessed so callers can apply their own retry or dead letter logic.
+// when a chunk fails the writes of that chunk and every chunk after it are returned as unprocessed along with the error
+func (d *DynamoDB) BatchWriteOnce(ctx context.Context, tableName string, writes []types.WriteRequest) (unprocessed []types.WriteRequest, err error) {
+	for start, end := 0, 0; start < len(writes); start = end {
+		end = batchWriteEnd(writes, start)
+
+		left, err := d.batchWriteOnce(ctx, tableName, writes[start:end])
+		if err != nil {
+			return append(unprocessed, writes[start:]...), fmt.Errorf("error writing items %d-%d: %w", start, end-1, err)
+		}
+		unprocessed = append(unprocessed, left...)
+	}
+
+	return unprocessed, nil
+}
+
+// batchWriteEnd returns where the chunk of writes starting at start ends, a chunk holds at most 25 requests and 16MB of items and always at least one request
+func batchWriteEnd(writes []types.WriteRequest, start int) int {
+	end := start
+	var size int
+	for end < len(writes) && end-start < maxBatchWriteItems {
+		size += writeRequestSize(writes[end])
+		if size > maxBatchWriteBytes && end > start {
+			break
+		}
+		end++
+	}
+	return end
+}
+
+// writeRequestSize approximates the payload of a write request, the item of a put or the key of a delete
+func writeRequestSize(w types.WriteRequest) int {
+	if w.PutRequest != nil {
+		return ItemSize(w.PutRequest.Item)
+	}
+	if w.DeleteRequest != nil {
+		return ItemSize(w.DeleteRequest.Key)
+	}
+	return 0
+}
+
+// batchWriteChunk writes up to 25 requests, retrying whatever dynamo reports back as unprocessed
+func (d *DynamoDB) batchWriteChunk(ctx context.Context, tableName string, writes []types.WriteRequest) error {
+	pending := writes
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			if attempt > maxBatchRetries {
+				return fmt.Errorf("%d unprocessed items remain after %d retries", len(pending), maxBatchRetries)
+			}
+			if !takeRetry(ctx) {
+				return fmt.Errorf("%w: %d unprocessed items remain", ErrRetryBudgetExhausted, len(pending))
+			}
+			if err := sleep(ctx, backoff(attempt)); err != nil {
+				return err
+			}
+			d.metrics.Retries.Add(1)
+		}
+
+		var err error
+		if pending, err = d.batchWriteOnce(ctx, tableName, pending); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// batchWriteOnce makes a single BatchWriteItem call for up to 25 requests and returns the unprocessed ones
+func (d *DynamoDB) batchWriteOnce(ctx context.Context, tableName string, writes []types.WriteRequest) ([]types.WriteRequest, error) {
+	if d.dryRun != nil {
+		// batchWriter reuses its buffer, record a copy of the requests
+		d.recordDryRun("BatchWriteItem", aws.String(tableName), len(writes), slices.Clone(writes))
+		return nil, nil
+	}
+
+	units := writeRequestUnits(writes)
+	if err := d.waitWrite(ctx, units); err != nil {
+		return nil, err
+	}
+
+	input := &dynamodb.BatchWriteItemInput{
+		RequestItems: map[string][]types.WriteRequest{tableName: writes},
+	}
+	if d.adaptive != nil {
+		if err := d.adaptive.wait(ctx, units); err != nil {
+			return nil, err
+		}
+		input.ReturnConsumedCapacity = types.ReturnConsumedCapacityTotal
+	}
+
+	output, err := call(ctx, d, "BatchWriteItem", aws.String(tableName), input, d.client.BatchWriteItem)
+	if err != nil {
+		if d.adaptive != nil && isThrottle(err) {
+			d.adaptive.throttled()
+		}
+		return nil, err
+	}
+
+	unprocessed := output.UnprocessedItems[tableName]
+	if d.adaptive != nil {
+		d.adaptive.observe(output.ConsumedCapacity, units-writeRequestUnits(unprocessed), len(unprocessed))
+	}
+
+	return unprocessed, nil
+}
+
+// backoff returns the exponential delay for the given retry attempt, capped at maxBatchBackoff
+func backoff(attempt int) time.Duration {
+	delay := baseBatchBackoff << (attempt - 1)
+	if delay <= 0 || delay > maxBatchBackoff {
+		return maxBatchBackoff
+	}
+	return delay
+}
+
+// sleep waits for the provided duration or until the context is done, whichever happens first
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// batchWriter buffers write requests and flushes them through BatchWrite every 25 items so streaming helpers never hold more than one batch in memory
+type batchWriter struct {
+	d         *DynamoDB
+	tableName string
+	pending   []types.WriteRequest
+	written   int
+}
+
+func (d *DynamoDB) newBatchWriter(tableName string) *batchWriter {
+	return &batchWriter{
+		d:         d,
+		tableName: tableName,
+		pending:   make([]types.WriteRequest, 0, maxBatchWriteItems),
+	}
+}
+
+// add queues a write request, flushing the buffer once it holds a full batch
+func (b *batchWriter) add(ctx context.Context, w types.WriteRequest) error {
+	b.pending = append(b.pending, w)
+	if len(b.pending) < maxBatchWriteItems {
+		return nil
+	}
+	return b.flush(ctx)
+}
+
+// flush writes any buffered requests
+func (b *batchWriter) flush(ctx context.Context) error {
+	if len(b.pending) == 0 {
+		return nil
+	}
+	if err := b.d.BatchWrite(ctx, b.tableName, b.pending); err != nil {
+		return err
+	}
+	b.written += len(b.pending)
+	b.pending = b.pending[:0]
+	return nil
+}
@@ -0,0 +1,118 @@
+package ddb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+	"github.com/jap1998/aws-code-snippets/aws/ddb/ddbtest"
+)
+
+// neverProcessed hands every batch write back as unprocessed, so batch writes keep backing off until they give up
+type neverProcessed struct {
+	*ddbtest.Fake
+	calls int
+}
+
+func (c *neverProcessed) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	c.calls++
+	return &dynamodb.BatchWriteItemOutput{UnprocessedItems: params.RequestItems}, nil
+}
+
+func TestBatchWriteDeadlineDuringBackoff(t *testing.T) {
+	_, f := newFake(t)
+	client := &neverProcessed{Fake: f}
+	d := ddb.NewWithClient(client)
+
+	// backoffs of 50, 100 and 200ms end at 350ms, the deadline lands inside the following 400ms one
+	ctx, cancel := context.WithTimeout(context.Background(), 400*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := d.BatchWrite(ctx, "t", []types.WriteRequest{{PutRequest: &types.PutRequest{Item: key("a", "1")}}})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed > 600*time.Millisecond {
+		t.Fatalf("expected the backoff to stop at the deadline, returned after %v", elapsed)
+	}
+	if client.calls < 2 {
+		t.Fatalf("expected the write to be retried before the deadline, got %d calls", client.calls)
+	}
+}
+
+// writeRecorder records the number of requests and the size of the items of every BatchWriteItem call
+type writeRecorder struct {
+	*ddbtest.Fake
+	counts []int
+	sizes  []int
+}
+
+func (c *writeRecorder) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	var count, size int
+	for _, writes := range params.RequestItems {
+		for _, w := range writes {
+			count++
+			size += ddb.ItemSize(w.PutRequest.Item)
+		}
+	}
+	c.counts = append(c.counts, count)
+	c.sizes = append(c.sizes, size)
+	return c.Fake.BatchWriteItem(ctx, params, optFns...)
+}
+
+func TestBatchWriteSplitsAtSizeLimit(t *testing.T) {
+	_, f := newFake(t)
+	rec := &writeRecorder{Fake: f}
+	d := ddb.NewWithClient(rec)
+
+	// 1MB items are over dynamo's 400KB item limit, the fake doesn't enforce it and 25 valid items can't reach 16MB
+	payload := &types.AttributeValueMemberS{Value: strings.Repeat("x", 1<<20)}
+	var writes []types.WriteRequest
+	for i := 0; i < 20; i++ {
+		item := key("a", fmt.Sprintf("%02d", i))
+		item["payload"] = payload
+		writes = append(writes, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+
+	if err := d.BatchWrite(context.Background(), "t", writes); err != nil {
+		t.Fatalf("batch write: %v", err)
+	}
+
+	if len(rec.counts) != 2 || rec.counts[0] != 15 || rec.counts[1] != 5 {
+		t.Fatalf("expected the 20 items to be split 15 and 5 by size, got %v", rec.counts)
+	}
+	for i, size := range rec.sizes {
+		if size > 16<<20 {
+			t.Fatalf("call %d: expected at most 16MB, got %d bytes", i, size)
+		}
+	}
+	if n := len(f.Items("t")); n != 20 {
+		t.Fatalf("expected 20 items written, got %d", n)
+	}
+}
+
+func TestBatchWriteSingleItemOverLimit(t *testing.T) {
+	_, f := newFake(t)
+	rec := &writeRecorder{Fake: f}
+	d := ddb.NewWithClient(rec)
+
+	item := key("a", "1")
+	item["payload"] = &types.AttributeValueMemberS{Value: strings.Repeat("x", 17<<20)}
+
+	// a chunk always holds at least one request, dynamo is left to reject it
+	if err := d.BatchWrite(context.Background(), "t", []types.WriteRequest{{PutRequest: &types.PutRequest{Item: item}}}); err != nil {
+		t.Fatalf("batch write: %v", err)
+	}
+	if len(rec.counts) != 1 || rec.counts[0] != 1 {
+		t.Fatalf("expected a single call with the item, got %v", rec.counts)
+	}
+}
@@ -0,0 +1,234 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxBatchGetItems is the maximum number of keys dynamo accepts in a single BatchGetItem call
+const maxBatchGetItems = 100
+
+// BatchGetItem is a wrapper around dynamodb.BatchGetItem with an already initialized client
+func (d *DynamoDB) BatchGetItem(ctx context.Context, input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	return call(ctx, d, "BatchGetItem", nil, input, d.client.BatchGetItem)
+}
+
+// BatchGet fetches the items for all the provided keys in chunks of 100, retrying unprocessed keys with exponential backoff.
+// the result is aligned with keys, result[i] is the item for keys[i] or nil when it doesn't exist, duplicate keys are only requested once
+func (d *DynamoDB) BatchGet(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) (_ []map[string]types.AttributeValue, err error) {
+	ctx, span := d.startSpan(ctx, "BatchGet", aws.String(tableName))
+	defer func() { endSpan(span, err) }()
+
+	unique := make([]map[string]types.AttributeValue, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		id := keyString(key)
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, key)
+		}
+	}
+
+	found := make(map[string]map[string]types.AttributeValue, len(unique))
+	for start := 0; start < len(unique); start += maxBatchGetItems {
+		end := min(start+maxBatchGetItems, len(unique))
+
+		items, err := d.batchGetChunk(ctx, tableName, unique[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("error getting keys %d-%d: %w", start, end-1, err)
+		}
+
+		for _, item := range items {
+			found[keyString(keyFor(item, unique[start]))] = item
+		}
+	}
+
+	results := make([]map[string]types.AttributeValue, len(keys))
+	for i, key := range keys {
+		results[i] = found[keyString(key)]
+	}
+
+	return results, nil
+}
+
+// BatchGetAs is a wrapper around BatchGet that unmarshals the items into T, keeping the order of keys and leaving the zero value of T for keys that don't exist
+func BatchGetAs[T any](ctx context.Context, d *DynamoDB, tableName string, keys []map[string]types.AttributeValue) ([]T, error) {
+	items, err := d.BatchGet(ctx, tableName, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]T, len(items))
+	for i, item := range items {
+		if item == nil {
+			continue
+		}
+		if err := d.unmarshalMap(item, &out[i]); err != nil {
+			return nil, fmt.Errorf("error unmarshalling item %d: %w", i, err)
+		}
+	}
+
+	return out, nil
+}
+
+// GetManyConcurrent fetches the items whose keyName attribute matches one of values, dispatching 100 key chunks to a pool of workers that each retry their own unprocessed keys.
+// items are returned in completion order unless preserveOrder is set, in which case they follow the order of values. keys that don't exist are left out and duplicate values are only requested once.
+// workers <= 0 uses the wrapper's WithMaxConcurrency, 4 by default, and anything above 16 is capped. a chunk that fails stops the others and only its error is returned, without the items already fetched
+func (d *DynamoDB) GetManyConcurrent(ctx context.Context, tableName, keyName string, values []types.AttributeValue, workers int, preserveOrder bool) (_ []map[string]types.AttributeValue, err error) {
+	ctx, span := d.startSpan(ctx, "GetManyConcurrent", aws.String(tableName))
+	defer func() { endSpan(span, err) }()
+
+	keys := make([]map[string]types.AttributeValue, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, v := range values {
+		key := map[string]types.AttributeValue{keyName: v}
+		if id := keyString(key); !seen[id] {
+			seen[id] = true
+			keys = append(keys, key)
+		}
+	}
+
+	var mu sync.Mutex
+	items := make([]map[string]types.AttributeValue, 0, len(keys))
+	chunks := (len(keys) + maxBatchGetItems - 1) / maxBatchGetItems
+
+	err = forEachConcurrent(ctx, d.workers(workers), chunks, func(ctx context.Context, i int) error {
+		start := i * maxBatchGetItems
+		end := min(start+maxBatchGetItems, len(keys))
+
+		got, err := d.batchGetChunk(ctx, tableName, keys[start:end])
+		if err != nil {
+			return fmt.Errorf("error getting keys %d-%d: %w", start, end-1, err)
+		}
+
+		mu.Lock()
+		items = append(items, got...)
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !preserveOrder {
+		return items, nil
+	}
+
+	found := make(map[string]map[string]types.AttributeValue, len(items))
+	for _, item := range items {
+		found[keyString(map[string]types.AttributeValue{keyName: item[keyName]})] = item
+	}
+
+	ordered := make([]map[string]types.AttributeValue, 0, len(items))
+	for _, key := range keys {
+		if item, ok := found[keyString(key)]; ok {
+			ordered = append(ordered, item)
+		}
+	}
+
+	return ordered, nil
+}
+
+// BatchGetOnce fetches the provided keys in chunks of 100 making a single attempt per chunk, it never retries and returns the keys dynamo left unprocessed alongside the items found.
+// keys must be unique, when a chunk fails the keys of that chunk and every chunk after it are returned as unprocessed along with the error
+func (d *DynamoDB) BatchGetOnce(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) (items, unprocessed []map[string]types.AttributeValue, err error) {
+	for start := 0; start < len(keys); start += maxBatchGetItems {
+		end := min(start+maxBatchGetItems, len(keys))
+
+		got, left, err := d.batchGetOnce(ctx, tableName, keys[start:end])
+		if err != nil {
+			return items, append(unprocessed, keys[start:]...), fmt.Errorf("error getting keys %d-%d: %w", start, end-1, err)
+		}
+		items = append(items, got...)
+		unprocessed = append(unprocessed, left...)
+	}
+
+	return items, unprocessed, nil
+}
+
+// batchGetChunk gets up to 100 keys, retrying whatever dynamo reports back as unprocessed
+func (d *DynamoDB) batchGetChunk(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	var items []map[string]types.AttributeValue
+	pending := keys
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		if attempt > 0 {
+			if attempt > maxBatchRetries {
+				return nil, fmt.Errorf("%d unprocessed keys remain after %d retries", len(pending), maxBatchRetries)
+			}
+			if !takeRetry(ctx) {
+				return nil, fmt.Errorf("%w: %d unprocessed keys remain", ErrRetryBudgetExhausted, len(pending))
+			}
+			if err := sleep(ctx, backoff(attempt)); err != nil {
+				return nil, err
+			}
+			d.metrics.Retries.Add(1)
+		}
+
+		got, left, err := d.batchGetOnce(ctx, tableName, pending)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, got...)
+		pending = left
+	}
+
+	return items, nil
+}
+
+// batchGetOnce makes a single BatchGetItem call for up to 100 keys and returns the items found and the unprocessed keys
+func (d *DynamoDB) batchGetOnce(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, []map[string]types.AttributeValue, error) {
+	output, err := call(ctx, d, "BatchGetItem", aws.String(tableName), &dynamodb.BatchGetItemInput{
+		RequestItems: map[string]types.KeysAndAttributes{tableName: {Keys: keys}},
+	}, d.client.BatchGetItem)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return output.Responses[tableName], output.UnprocessedKeys[tableName].Keys, nil
+}
+
+// keyFor extracts from item the attributes that make up a key with the same attribute names as ref
+func keyFor(item, ref map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := make(map[string]types.AttributeValue, len(ref))
+	for name := range ref {
+		key[name] = item[name]
+	}
+	return key
+}
+
+// keyString returns a string that identifies key by the content of its attributes, so equal keys built separately compare equal.
+// every name and value is length prefixed, S and B values can hold any byte so no separator could keep two different keys apart
+func keyString(key map[string]types.AttributeValue) string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&sb, "%d:%s", len(name), name)
+		switch v := key[name].(type) {
+		case *types.AttributeValueMemberS:
+			fmt.Fprintf(&sb, "S%d:%s", len(v.Value), v.Value)
+		case *types.AttributeValueMemberN:
+			fmt.Fprintf(&sb, "N%d:%s", len(v.Value), v.Value)
+		case *types.AttributeValueMemberB:
+			fmt.Fprintf(&sb, "B%d:%s", len(v.Value), v.Value)
+		default:
+			t := fmt.Sprintf("%T", v)
+			fmt.Fprintf(&sb, "?%d:%s", len(t), t)
+		}
+	}
+
+	return sb.String()
+}
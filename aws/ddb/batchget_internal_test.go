@@ -0,0 +1,34 @@
+package ddb
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestKeyStringNoCollisions(t *testing.T) {
+	s := func(v string) types.AttributeValue { return &types.AttributeValueMemberS{Value: v} }
+	b := func(v string) types.AttributeValue { return &types.AttributeValueMemberB{Value: []byte(v)} }
+
+	pairs := [][2]map[string]types.AttributeValue{
+		// separators inside values
+		{{"a": s("x\x00b\x00Sy"), "b": s("z")}, {"a": s("x"), "b": s("y\x00b\x00Sz")}},
+		{{"a": b("x\x00b\x00By"), "b": b("z")}, {"a": b("x"), "b": b("y\x00b\x00Bz")}},
+		// same bytes under a different type
+		{{"a": s("1")}, {"a": &types.AttributeValueMemberN{Value: "1"}}},
+		{{"a": s("x")}, {"a": b("x")}},
+		// a name swallowing part of a value
+		{{"a": s("b")}, {"ab": s("")}},
+	}
+
+	for i, p := range pairs {
+		if keyString(p[0]) == keyString(p[1]) {
+			t.Errorf("pair %d: %v and %v give the same key string %q", i, p[0], p[1], keyString(p[0]))
+		}
+	}
+
+	// equal keys built separately still match, binary ones by content
+	if keyString(map[string]types.AttributeValue{"a": b("\x00\x01")}) != keyString(map[string]types.AttributeValue{"a": b("\x00\x01")}) {
+		t.Error("expected equal binary keys to give the same key string")
+	}
+}
@@ -0,0 +1,139 @@
+package ddb_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+	"github.com/jap1998/aws-code-snippets/aws/ddb/ddbtest"
+)
+
+// chunkRecorder records the number of keys of every BatchGetItem call
+type chunkRecorder struct {
+	*ddbtest.Fake
+	chunks []int
+}
+
+func (c *chunkRecorder) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	n := 0
+	for _, req := range params.RequestItems {
+		n += len(req.Keys)
+	}
+	c.chunks = append(c.chunks, n)
+	return c.Fake.BatchGetItem(ctx, params, optFns...)
+}
+
+func TestBatchGetAsChunksAndKeepsOrder(t *testing.T) {
+	_, f := newFake(t)
+	rec := &chunkRecorder{Fake: f}
+	d := ddb.NewWithClient(rec)
+
+	var sks []string
+	for i := 0; i < 150; i++ {
+		sks = append(sks, fmt.Sprintf("%03d", i))
+	}
+	putItems(t, f, "a", sks...)
+
+	// 251 keys walking the items backward, a miss after two of every three and a duplicate at the end
+	var keys []map[string]types.AttributeValue
+	var want []string
+	for i := 149; i >= 0; i-- {
+		keys = append(keys, key("a", fmt.Sprintf("%03d", i)))
+		want = append(want, fmt.Sprintf("%03d", i))
+		if i%3 != 0 {
+			keys = append(keys, key("missing", fmt.Sprintf("%03d", i)))
+			want = append(want, "")
+		}
+	}
+	keys = append(keys, key("a", "000"))
+	want = append(want, "000")
+
+	if len(keys) <= 200 {
+		t.Fatalf("expected more than two chunks of keys, got %d", len(keys))
+	}
+
+	got, err := ddb.BatchGetAs[row](context.Background(), d, "t", keys)
+	if err != nil {
+		t.Fatalf("batch get: %v", err)
+	}
+
+	if len(rec.chunks) < 2 {
+		t.Fatalf("expected the keys to be split in several calls, got %v", rec.chunks)
+	}
+	for _, n := range rec.chunks {
+		if n > 100 {
+			t.Fatalf("expected at most 100 keys per call, got %v", rec.chunks)
+		}
+	}
+
+	if len(got) != len(keys) {
+		t.Fatalf("expected %d results, got %d", len(keys), len(got))
+	}
+	for i, r := range got {
+		if want[i] == "" {
+			if r != (row{}) {
+				t.Fatalf("result %d: expected the zero value for a missing key, got %+v", i, r)
+			}
+			continue
+		}
+		if r.PK != "a" || r.SK != want[i] {
+			t.Fatalf("result %d: expected a/%s, got %+v", i, want[i], r)
+		}
+	}
+}
+
+func TestBatchGetBinaryPartitionKey(t *testing.T) {
+	f := ddbtest.NewFake()
+	_, err := f.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName:            aws.String("bin"),
+		KeySchema:            []types.KeySchemaElement{{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash}},
+		AttributeDefinitions: []types.AttributeDefinition{{AttributeName: aws.String("id"), AttributeType: types.ScalarAttributeTypeB}},
+	})
+	if err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	rec := &chunkRecorder{Fake: f}
+	d := ddb.NewWithClient(rec)
+
+	ids := [][]byte{{0x00, 0x01}, {0x00, 0x02}, {0xff}}
+	for i, id := range ids[:2] {
+		item := ddb.KeyB("id", id)
+		item["n"] = &types.AttributeValueMemberN{Value: fmt.Sprint(i)}
+		if _, err := d.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("bin"), Item: item}); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+	}
+
+	// the duplicate is a separate slice with the same bytes
+	keys := []map[string]types.AttributeValue{
+		ddb.KeyB("id", ids[0]),
+		ddb.KeyB("id", ids[1]),
+		ddb.KeyB("id", ids[2]),
+		ddb.KeyB("id", []byte{0x00, 0x01}),
+	}
+
+	got, err := d.BatchGet(context.Background(), "bin", keys)
+	if err != nil {
+		t.Fatalf("batch get: %v", err)
+	}
+
+	if rec.chunks[0] != 3 {
+		t.Fatalf("expected the duplicate binary key to be requested once, got %d keys", rec.chunks[0])
+	}
+
+	want := []string{"0", "1", "", "0"}
+	for i, item := range got {
+		n, _ := item["n"].(*types.AttributeValueMemberN)
+		switch {
+		case want[i] == "" && item != nil:
+			t.Fatalf("result %d: expected no item, got %v", i, item)
+		case want[i] != "" && (n == nil || n.Value != want[i]):
+			t.Fatalf("result %d: expected item %s, got %v", i, want[i], item)
+		}
+	}
+}
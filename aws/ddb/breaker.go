@@ -0,0 +1,62 @@
+package ddb
+
+import (
+	"sync"
+	"time"
+)
+
+// circuitBreaker trips after threshold consecutive server-side failures and rejects calls until cooldown has passed, then lets a single probe through
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// WithCircuitBreaker short-circuits calls with ErrCircuitOpen for cooldown once threshold consecutive calls failed server-side, then half-opens and lets one probe call through.
+// only throttling, server faults and transport errors count as failures, client errors like ConditionalCheckFailed don't trip the breaker
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(d *DynamoDB) {
+		d.breaker = &circuitBreaker{
+			threshold: max(1, threshold),
+			cooldown:  cooldown,
+		}
+	}
+}
+
+// allow reports whether a call may go through, while half open only the first caller gets to probe
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+
+	if time.Now().Before(b.openUntil) || b.probing {
+		return false
+	}
+
+	b.probing = true
+	return true
+}
+
+// record updates the breaker with the outcome of an allowed call
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if !isServerFailure(err) {
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openUntil = time.Now().Add(b.cooldown)
+	}
+}
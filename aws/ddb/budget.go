@@ -0,0 +1,19 @@
+package ddb
+
+import "fmt"
+
+// WithScanBudget caps how many items ScanAll, ScanWithFilter, ScanPages and the count scans may read in a single call, summing ScannedCount across pages.
+// once the budget is reached and more pages remain paging stops, returning what matched so far along with ErrBudgetExceeded. the check happens between pages so the last page can overshoot the budget by up to 1MB of reads
+func WithScanBudget(maxScanned int) Option {
+	return func(d *DynamoDB) {
+		d.scanBudget = maxScanned
+	}
+}
+
+// checkScanBudget returns ErrBudgetExceeded when a budget is set and scanned items reached it
+func (d *DynamoDB) checkScanBudget(scanned int) error {
+	if d.scanBudget <= 0 || scanned < d.scanBudget {
+		return nil
+	}
+	return fmt.Errorf("%w: scanned %d items with a budget of %d", ErrBudgetExceeded, scanned, d.scanBudget)
+}
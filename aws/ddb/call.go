@@ -0,0 +1,64 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// call runs a single dynamo API call, every request the wrapper sends goes through here so cross-cutting behavior like tracing and logging lives in one place
+func call[In, Out any](ctx context.Context, d *DynamoDB, operation string, tableName *string, input In, fn func(context.Context, In, ...func(*dynamodb.Options)) (Out, error)) (Out, error) {
+	if d.defaultTimeout > 0 {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d.defaultTimeout)
+			defer cancel()
+		}
+	}
+
+	input = withReadDefaults(ctx, d, input)
+
+	ctx, span := d.startSpan(ctx, operation, tableName)
+
+	var start time.Time
+	if d.logger != nil {
+		start = time.Now()
+	}
+
+	var output Out
+	var err error
+
+	if d.breaker != nil && !d.breaker.allow() {
+		err = ErrCircuitOpen
+	} else {
+		output, err = fn(ctx, input)
+
+		if d.shouldAutoCreate(operation, tableName, err) {
+			if cerr := d.createTable(ctx); cerr != nil {
+				err = errors.Join(err, fmt.Errorf("error auto creating table: %w", cerr))
+			} else {
+				output, err = fn(ctx, input)
+			}
+		}
+
+		if d.breaker != nil {
+			d.breaker.record(err)
+		}
+		d.metrics.observe(err)
+	}
+
+	if d.logger != nil {
+		d.logCall(ctx, operation, tableName, input, output, time.Since(start), err)
+	}
+
+	if err == nil && span.IsRecording() {
+		span.SetAttributes(attribute.Float64("db.consumed_capacity", consumedCapacity(output)))
+	}
+	endSpan(span, err)
+
+	return output, err
+}
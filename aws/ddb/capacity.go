@@ -0,0 +1,39 @@
+package ddb
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GetOneWithCapacity is like GetOne but asks dynamo for the TOTAL consumed capacity and returns it alongside the item.
+// the capacity is returned with ErrNotFound too since a miss still consumes read capacity
+func (d *DynamoDB) GetOneWithCapacity(ctx context.Context, input *dynamodb.GetItemInput) (map[string]types.AttributeValue, *types.ConsumedCapacity, error) {
+	in := *input
+	in.ReturnConsumedCapacity = types.ReturnConsumedCapacityTotal
+
+	output, err := d.GetItem(ctx, &in)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if output.Item == nil {
+		return nil, output.ConsumedCapacity, ErrNotFound
+	}
+
+	return output.Item, output.ConsumedCapacity, nil
+}
+
+// PutWithCapacity is a wrapper around PutItem that asks dynamo for the TOTAL consumed capacity and returns it
+func (d *DynamoDB) PutWithCapacity(ctx context.Context, input *dynamodb.PutItemInput) (*types.ConsumedCapacity, error) {
+	in := *input
+	in.ReturnConsumedCapacity = types.ReturnConsumedCapacityTotal
+
+	output, err := d.PutItem(ctx, &in)
+	if err != nil {
+		return nil, err
+	}
+
+	return output.ConsumedCapacity, nil
+}
@@ -0,0 +1,39 @@
+package ddb
+
+import (
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// WithUseNumber makes the typed helpers decode numbers into interface{} or any fields as attributevalue.Number, the exact string dynamo stores, instead of float64 which loses precision past 2^53.
+// integer and float fields are decoded the same way with or without it, it only matters for untyped fields and maps
+func WithUseNumber() Option {
+	return func(d *DynamoDB) {
+		d.decoderOpts = append(d.decoderOpts, func(o *attributevalue.DecoderOptions) {
+			o.UseNumber = true
+		})
+	}
+}
+
+// WithTagKey makes the typed helpers read and write attribute names from the tag struct tag instead of dynamodbav, e.g. "json" to reuse structs already tagged for json.
+// the tag is parsed the same way as dynamodbav so options like omitempty or "-" keep working
+func WithTagKey(tag string) Option {
+	return func(d *DynamoDB) {
+		d.decoderOpts = append(d.decoderOpts, func(o *attributevalue.DecoderOptions) {
+			o.TagKey = tag
+		})
+		d.encoderOpts = append(d.encoderOpts, func(o *attributevalue.EncoderOptions) {
+			o.TagKey = tag
+		})
+	}
+}
+
+// unmarshalMap unmarshals item into out with the decoder options the wrapper was configured with
+func (d *DynamoDB) unmarshalMap(item map[string]types.AttributeValue, out any) error {
+	return attributevalue.UnmarshalMapWithOptions(item, out, d.decoderOpts...)
+}
+
+// marshalMap marshals in into an item with the encoder options the wrapper was configured with
+func (d *DynamoDB) marshalMap(in any) (map[string]types.AttributeValue, error) {
+	return attributevalue.MarshalMapWithOptions(in, d.encoderOpts...)
+}
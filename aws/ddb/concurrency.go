@@ -0,0 +1,92 @@
+package ddb
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// WithMaxConcurrency sets how many requests the parallel helpers run at a time when a call doesn't ask for its own worker count, 4 when it isn't set.
+// n <= 0 derives it from GOMAXPROCS, twice the number of usable CPUs, and any value is capped at 16 so a single call can't exhaust connections or hammer the table
+func WithMaxConcurrency(n int) Option {
+	return func(d *DynamoDB) {
+		if n <= 0 {
+			n = 2 * runtime.GOMAXPROCS(0)
+		}
+		d.maxConcurrency = n
+	}
+}
+
+// workers returns the pool size of a parallel helper, n when the call asks for one, the wrapper's concurrency otherwise, capped at 16
+func (d *DynamoDB) workers(n int) int {
+	if n <= 0 {
+		n = d.maxConcurrency
+	}
+	if n <= 0 {
+		n = defaultBatchWorkers
+	}
+	return min(n, maxBatchWorkers)
+}
+
+// forEachConcurrent calls fn with every index from 0 to n-1 from a pool of workers goroutines, see feedConcurrent for how errors stop it
+func forEachConcurrent(ctx context.Context, workers, n int, fn func(ctx context.Context, i int) error) error {
+	return feedConcurrent(ctx, workers, func(_ context.Context, send func(int) bool) error {
+		for i := 0; i < n; i++ {
+			if !send(i) {
+				break
+			}
+		}
+		return nil
+	}, fn)
+}
+
+// feedConcurrent runs fn from a pool of workers goroutines for every job feed hands to send, the shared worker pool behind the parallel helpers.
+// the first error fn returns cancels the context of every other call and of feed, send then returns false so feed can stop early. that error is returned first,
+// then feed's own error and finally the error of ctx when it ended before every job was handed out. fn runs concurrently and must guard any state it shares
+func feedConcurrent[T any](ctx context.Context, workers int, feed func(ctx context.Context, send func(T) bool) error, fn func(ctx context.Context, job T) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	jobs := make(chan T)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				if err := fn(ctx, job); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+					return
+				}
+			}
+		}()
+	}
+
+	send := func(job T) bool {
+		select {
+		case jobs <- job:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	feedErr := feed(ctx, send)
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	if feedErr != nil {
+		return feedErr
+	}
+
+	return ctx.Err()
+}
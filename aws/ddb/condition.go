@@ -0,0 +1,139 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// placeholder matches the #name and :value placeholders of an expression
+var placeholder = regexp.MustCompile(`[#:][A-Za-z0-9_]+`)
+
+// conditionInput is an input that carries a ConditionExpression
+type conditionInput interface {
+	*dynamodb.PutItemInput | *dynamodb.UpdateItemInput | *dynamodb.DeleteItemInput
+}
+
+// UpdateIf is a wrapper around dynamodb.UpdateItem that only applies the update when condition holds, checked atomically by dynamo in the same call.
+// the condition is ANDed with any ConditionExpression already on the input and a *ConditionError is returned when it doesn't hold, set ReturnValuesOnConditionCheckFailure to ALL_OLD on the input to get the current item in it.
+// the merge is done on a copy, the input is not modified so it can be reused or retried without the condition being added again
+func (d *DynamoDB) UpdateIf(ctx context.Context, input *dynamodb.UpdateItemInput, condition expression.ConditionBuilder) error {
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		return fmt.Errorf("error building condition: %w", err)
+	}
+
+	in := *input
+	in.ExpressionAttributeNames = maps.Clone(input.ExpressionAttributeNames)
+	in.ExpressionAttributeValues = maps.Clone(input.ExpressionAttributeValues)
+
+	MergeCondition(&in, aws.ToString(expr.Condition()), expr.Names(), expr.Values())
+
+	_, err = d.UpdateItem(ctx, &in)
+
+	return mapConditionErr(err)
+}
+
+// MergeCondition ANDs extraExpr with the ConditionExpression already on input and merges names and values into its expression attribute maps, so helpers can add a condition without dropping the caller's.
+// a placeholder of extraExpr already used by the input for something else is re-aliased in extraExpr, one mapped to the same name or value is shared. the input's maps are replaced by merged copies, never modified in place
+func MergeCondition[T conditionInput](input T, extraExpr string, names map[string]string, values map[string]types.AttributeValue) {
+	var cond **string
+	var inNames *map[string]string
+	var inValues *map[string]types.AttributeValue
+
+	switch in := any(input).(type) {
+	case *dynamodb.PutItemInput:
+		cond, inNames, inValues = &in.ConditionExpression, &in.ExpressionAttributeNames, &in.ExpressionAttributeValues
+	case *dynamodb.UpdateItemInput:
+		cond, inNames, inValues = &in.ConditionExpression, &in.ExpressionAttributeNames, &in.ExpressionAttributeValues
+	case *dynamodb.DeleteItemInput:
+		cond, inNames, inValues = &in.ConditionExpression, &in.ExpressionAttributeNames, &in.ExpressionAttributeValues
+	}
+
+	mergedNames := maps.Clone(*inNames)
+	if mergedNames == nil && len(names) > 0 {
+		mergedNames = make(map[string]string, len(names))
+	}
+	mergedValues := maps.Clone(*inValues)
+	if mergedValues == nil && len(values) > 0 {
+		mergedValues = make(map[string]types.AttributeValue, len(values))
+	}
+
+	rename := make(map[string]string)
+	for k, v := range names {
+		rename[k] = aliasName(mergedNames, k, v)
+	}
+	for k, v := range values {
+		alias := k
+		for i := 1; ; i++ {
+			existing, taken := mergedValues[alias]
+			if !taken || reflect.DeepEqual(existing, v) {
+				break
+			}
+			alias = fmt.Sprintf("%s_%d", k, i)
+		}
+		mergedValues[alias] = v
+		rename[k] = alias
+	}
+
+	extraExpr = placeholder.ReplaceAllStringFunc(extraExpr, func(p string) string {
+		if alias, ok := rename[p]; ok {
+			return alias
+		}
+		return p
+	})
+
+	if existing := aws.ToString(*cond); existing != "" {
+		extraExpr = fmt.Sprintf("(%s) AND (%s)", existing, extraExpr)
+	}
+
+	*cond = aws.String(extraExpr)
+	*inNames = mergedNames
+	*inValues = mergedValues
+}
+
+// aliasName adds name to names under alias, or under alias_1, alias_2... when alias is already used for a different name, and returns the alias it ended up under
+func aliasName(names map[string]string, alias, name string) string {
+	k := alias
+	for i := 1; ; i++ {
+		existing, taken := names[k]
+		if !taken || existing == name {
+			break
+		}
+		k = fmt.Sprintf("%s_%d", alias, i)
+	}
+	names[k] = name
+	return k
+}
+
+// ConditionError is returned when dynamo rejects a write because its condition didn't hold, it matches ErrConditionFailed with errors.Is.
+// Item is the item as it was when the write was rejected, it is only sent back when the input set ReturnValuesOnConditionCheckFailure to ALL_OLD and is nil otherwise or when there was no item
+type ConditionError struct {
+	Item map[string]types.AttributeValue
+	Err  error
+}
+
+func (e *ConditionError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrConditionFailed, e.Err)
+}
+
+func (e *ConditionError) Unwrap() []error {
+	return []error{ErrConditionFailed, e.Err}
+}
+
+// mapConditionErr turns a ConditionalCheckFailedException into a *ConditionError carrying the current item, if any, and leaves any other error untouched
+func mapConditionErr(err error) error {
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return &ConditionError{Item: ccf.Item, Err: err}
+	}
+	return err
+}
@@ -0,0 +1,90 @@
+package ddb_test
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+)
+
+func str(s string) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: s}
+}
+
+func TestMergeConditionReAliasesCollisions(t *testing.T) {
+	callerNames := map[string]string{"#a": "status", "#b": "owner"}
+	callerValues := map[string]types.AttributeValue{":v": str("open"), ":w": str("me")}
+	input := &dynamodb.DeleteItemInput{
+		ConditionExpression:       aws.String("#a = :v AND #b = :w"),
+		ExpressionAttributeNames:  callerNames,
+		ExpressionAttributeValues: callerValues,
+	}
+
+	// #a and :v collide with different meanings, #b and :w map to the same name and value as the caller's
+	ddb.MergeCondition(input, "#a <> :v AND #b = :w",
+		map[string]string{"#a": "state", "#b": "owner"},
+		map[string]types.AttributeValue{":v": str("closed"), ":w": str("me")},
+	)
+
+	if want := "(#a = :v AND #b = :w) AND (#a_1 <> :v_1 AND #b = :w)"; aws.ToString(input.ConditionExpression) != want {
+		t.Fatalf("expected %q, got %q", want, aws.ToString(input.ConditionExpression))
+	}
+
+	wantNames := map[string]string{"#a": "status", "#b": "owner", "#a_1": "state"}
+	if !maps.Equal(input.ExpressionAttributeNames, wantNames) {
+		t.Fatalf("expected names %v, got %v", wantNames, input.ExpressionAttributeNames)
+	}
+	if len(input.ExpressionAttributeValues) != 3 {
+		t.Fatalf("expected 3 values, got %v", input.ExpressionAttributeValues)
+	}
+	if v, ok := input.ExpressionAttributeValues[":v_1"].(*types.AttributeValueMemberS); !ok || v.Value != "closed" {
+		t.Fatalf("expected :v_1 to hold the re-aliased value, got %v", input.ExpressionAttributeValues[":v_1"])
+	}
+
+	if len(callerNames) != 2 || len(callerValues) != 2 {
+		t.Fatalf("expected the caller's maps to be left untouched, got %v and %v", callerNames, callerValues)
+	}
+
+	// an alias already taken by an earlier re-alias moves on to the next suffix
+	ddb.MergeCondition(input, "#a <> :v", map[string]string{"#a": "kind"}, map[string]types.AttributeValue{":v": str("x")})
+	if input.ExpressionAttributeNames["#a_2"] != "kind" {
+		t.Fatalf("expected #a_2 for the second collision, got %v", input.ExpressionAttributeNames)
+	}
+}
+
+func TestMergeConditionEvaluatesBothConditions(t *testing.T) {
+	_, f := newFake(t)
+	item := key("a", "1")
+	item["status"] = str("open")
+	item["state"] = str("closed")
+	if _, err := f.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("t"), Item: item}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	deleteIf := func(extraState string) error {
+		input := &dynamodb.DeleteItemInput{
+			TableName:                 aws.String("t"),
+			Key:                       key("a", "1"),
+			ConditionExpression:       aws.String("#a = :v"),
+			ExpressionAttributeNames:  map[string]string{"#a": "status"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":v": str("open")},
+		}
+		ddb.MergeCondition(input, "#a = :v", map[string]string{"#a": "state"}, map[string]types.AttributeValue{":v": str(extraState)})
+
+		_, err := f.DeleteItem(context.Background(), input)
+		return err
+	}
+
+	var ccf *types.ConditionalCheckFailedException
+	if err := deleteIf("open"); !errors.As(err, &ccf) {
+		t.Fatalf("expected the merged condition to fail on state, got %v", err)
+	}
+	if err := deleteIf("closed"); err != nil {
+		t.Fatalf("expected both conditions to hold, got %v", err)
+	}
+}
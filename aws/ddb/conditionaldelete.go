@@ -0,0 +1,70 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ConditionalDelete is a delete that only applies when Condition holds on the current item, e.g. a version check
+type ConditionalDelete struct {
+	Key       map[string]types.AttributeValue
+	Condition expression.ConditionBuilder
+}
+
+// ConditionalDeleteMany deletes every key whose condition holds and returns the keys whose condition didn't, in the order they were provided.
+// BatchWriteItem can't carry conditions so each delete is its own DeleteItem, issued by a pool of workers, workers <= 0 uses the wrapper's
+// WithMaxConcurrency, 4 by default, capped at 16. a failed condition is not an error, any other failure cancels the remaining deletes and its error is returned along with the failed conditions seen so far
+func (d *DynamoDB) ConditionalDeleteMany(ctx context.Context, tableName string, deletes []ConditionalDelete, workers int) (failed []map[string]types.AttributeValue, err error) {
+	ctx, span := d.startSpan(ctx, "ConditionalDeleteMany", aws.String(tableName))
+	defer func() { endSpan(span, err) }()
+
+	var mu sync.Mutex
+	var failedIdx []int
+
+	err = forEachConcurrent(ctx, d.workers(workers), len(deletes), func(ctx context.Context, i int) error {
+		err := d.conditionalDelete(ctx, tableName, deletes[i])
+		if errors.Is(err, ErrConditionFailed) {
+			mu.Lock()
+			failedIdx = append(failedIdx, i)
+			mu.Unlock()
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error deleting item %d: %w", i, err)
+		}
+		return nil
+	})
+
+	sort.Ints(failedIdx)
+	for _, i := range failedIdx {
+		failed = append(failed, deletes[i].Key)
+	}
+
+	return failed, err
+}
+
+// conditionalDelete deletes a single item when its condition holds, a failed condition is returned as ErrConditionFailed
+func (d *DynamoDB) conditionalDelete(ctx context.Context, tableName string, del ConditionalDelete) error {
+	expr, err := expression.NewBuilder().WithCondition(del.Condition).Build()
+	if err != nil {
+		return fmt.Errorf("error building condition: %w", err)
+	}
+
+	_, err = d.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(tableName),
+		Key:                       del.Key,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+
+	return mapConditionErr(err)
+}
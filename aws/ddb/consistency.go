@@ -0,0 +1,78 @@
+package ddb
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// WithConsistentReads makes GetItem, Query and Scan calls, and every helper built on them, strongly consistent unless the input sets ConsistentRead explicitly.
+// queries and scans on a global secondary index are left as they are since those can't be read consistently, the skipped default is logged at debug level when WithLogger is used.
+// local secondary indexes do support it and get the default, the two are told apart from the table's cached DescribeTable
+func WithConsistentReads() Option {
+	return func(d *DynamoDB) {
+		d.consistent = true
+	}
+}
+
+// withReadDefaults returns input with ConsistentRead defaulted to true when WithConsistentReads is used, the caller's input is copied rather than modified
+func withReadDefaults[In any](ctx context.Context, d *DynamoDB, input In) In {
+	if !d.consistent {
+		return input
+	}
+
+	switch in := any(input).(type) {
+	case *dynamodb.GetItemInput:
+		if in.ConsistentRead == nil {
+			c := *in
+			c.ConsistentRead = aws.Bool(true)
+			return any(&c).(In)
+		}
+	case *dynamodb.QueryInput:
+		if in.ConsistentRead == nil {
+			if in.IndexName != nil && d.globalIndex(ctx, in.TableName, in.IndexName) {
+				d.logSkippedConsistentRead(ctx, "Query", in.TableName, in.IndexName)
+				return input
+			}
+			c := *in
+			c.ConsistentRead = aws.Bool(true)
+			return any(&c).(In)
+		}
+	case *dynamodb.ScanInput:
+		if in.ConsistentRead == nil {
+			if in.IndexName != nil && d.globalIndex(ctx, in.TableName, in.IndexName) {
+				d.logSkippedConsistentRead(ctx, "Scan", in.TableName, in.IndexName)
+				return input
+			}
+			c := *in
+			c.ConsistentRead = aws.Bool(true)
+			return any(&c).(In)
+		}
+	}
+
+	return input
+}
+
+// globalIndex reports whether indexName is a global secondary index of the table. local ones can only be created along with the table, so an index the cached
+// description doesn't list, or a table that can't be described, is taken as global rather than risk a consistent read dynamo rejects
+func (d *DynamoDB) globalIndex(ctx context.Context, tableName, indexName *string) bool {
+	entry, err := d.tableSchema(ctx, aws.ToString(tableName))
+	if err != nil {
+		return true
+	}
+	idx, ok := entry.indexes[aws.ToString(indexName)]
+	return !ok || idx.global
+}
+
+func (d *DynamoDB) logSkippedConsistentRead(ctx context.Context, operation string, tableName, indexName *string) {
+	if d.logger == nil {
+		return
+	}
+	d.logger.LogAttrs(ctx, slog.LevelDebug, "consistent read default skipped for index",
+		slog.String("operation", operation),
+		slog.String("table", aws.ToString(tableName)),
+		slog.String("index", aws.ToString(indexName)),
+	)
+}
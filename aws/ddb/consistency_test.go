@@ -0,0 +1,74 @@
+package ddb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+	"github.com/jap1998/aws-code-snippets/aws/ddb/ddbtest"
+)
+
+// consistencyRecorder records the ConsistentRead of every Query call
+type consistencyRecorder struct {
+	*ddbtest.Fake
+	consistent []*bool
+}
+
+func (c *consistencyRecorder) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	c.consistent = append(c.consistent, params.ConsistentRead)
+	return c.Fake.Query(ctx, params, optFns...)
+}
+
+func TestConsistentReadsDefaultOnLocalIndexOnly(t *testing.T) {
+	f := ddbtest.NewFake()
+	_, err := f.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("t"),
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("sk"), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName:  aws.String("gsi"),
+			KeySchema:  []types.KeySchemaElement{{AttributeName: aws.String("gpk"), KeyType: types.KeyTypeHash}},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}},
+		LocalSecondaryIndexes: []types.LocalSecondaryIndex{{
+			IndexName: aws.String("lsi"),
+			KeySchema: []types.KeySchemaElement{
+				{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+				{AttributeName: aws.String("alt"), KeyType: types.KeyTypeRange},
+			},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeAll},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	rec := &consistencyRecorder{Fake: f}
+	d := ddb.NewWithClient(rec, ddb.WithConsistentReads())
+
+	for _, index := range []string{"lsi", "gsi"} {
+		attr := "pk"
+		if index == "gsi" {
+			attr = "gpk"
+		}
+		_, err := d.Query(context.Background(), &dynamodb.QueryInput{
+			TableName:                 aws.String("t"),
+			IndexName:                 aws.String(index),
+			KeyConditionExpression:    aws.String("#k = :k"),
+			ExpressionAttributeNames:  map[string]string{"#k": attr},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":k": &types.AttributeValueMemberS{Value: "a"}},
+		})
+		if err != nil {
+			t.Fatalf("query %s: %v", index, err)
+		}
+	}
+
+	if len(rec.consistent) != 2 || !aws.ToBool(rec.consistent[0]) || rec.consistent[1] != nil {
+		t.Fatalf("expected a consistent read on the local index only, got %v", rec.consistent)
+	}
+}
@@ -0,0 +1,129 @@
+package ddb
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ToPlainMap converts a dynamo item into a map of plain go values that can be encoded as JSON.
+// numbers become json.Number to keep their precision, binary values become []byte and sets become slices
+func ToPlainMap(item map[string]types.AttributeValue) (map[string]any, error) {
+	m := make(map[string]any, len(item))
+
+	for k, av := range item {
+		v, err := toPlainValue(av)
+		if err != nil {
+			return nil, fmt.Errorf("error converting attribute %q: %w", k, err)
+		}
+		m[k] = v
+	}
+
+	return m, nil
+}
+
+func toPlainValue(av types.AttributeValue) (any, error) {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value, nil
+	case *types.AttributeValueMemberN:
+		return json.Number(v.Value), nil
+	case *types.AttributeValueMemberB:
+		return v.Value, nil
+	case *types.AttributeValueMemberBOOL:
+		return v.Value, nil
+	case *types.AttributeValueMemberNULL:
+		return nil, nil
+	case *types.AttributeValueMemberSS:
+		return v.Value, nil
+	case *types.AttributeValueMemberNS:
+		ns := make([]json.Number, len(v.Value))
+		for i, n := range v.Value {
+			ns[i] = json.Number(n)
+		}
+		return ns, nil
+	case *types.AttributeValueMemberBS:
+		return v.Value, nil
+	case *types.AttributeValueMemberL:
+		l := make([]any, len(v.Value))
+		for i, e := range v.Value {
+			pv, err := toPlainValue(e)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = pv
+		}
+		return l, nil
+	case *types.AttributeValueMemberM:
+		return ToPlainMap(v.Value)
+	default:
+		return nil, fmt.Errorf("unsupported attribute value type %T", av)
+	}
+}
+
+// FromPlainMap converts a map of plain go values, such as one decoded from JSON with UseNumber, back into a dynamo item.
+// it is the inverse of ToPlainMap except that information lost in JSON (sets and binary values) comes back as lists and strings
+func FromPlainMap(m map[string]any) (map[string]types.AttributeValue, error) {
+	item := make(map[string]types.AttributeValue, len(m))
+
+	for k, v := range m {
+		av, err := fromPlainValue(v)
+		if err != nil {
+			return nil, fmt.Errorf("error converting attribute %q: %w", k, err)
+		}
+		item[k] = av
+	}
+
+	return item, nil
+}
+
+func fromPlainValue(v any) (types.AttributeValue, error) {
+	switch v := v.(type) {
+	case nil:
+		return &types.AttributeValueMemberNULL{Value: true}, nil
+	case string:
+		return &types.AttributeValueMemberS{Value: v}, nil
+	case json.Number:
+		return &types.AttributeValueMemberN{Value: v.String()}, nil
+	case float64:
+		return &types.AttributeValueMemberN{Value: strconv.FormatFloat(v, 'f', -1, 64)}, nil
+	case int:
+		return &types.AttributeValueMemberN{Value: strconv.Itoa(v)}, nil
+	case int64:
+		return &types.AttributeValueMemberN{Value: strconv.FormatInt(v, 10)}, nil
+	case bool:
+		return &types.AttributeValueMemberBOOL{Value: v}, nil
+	case []byte:
+		return &types.AttributeValueMemberB{Value: v}, nil
+	case []string:
+		return &types.AttributeValueMemberSS{Value: v}, nil
+	case []json.Number:
+		ns := make([]string, len(v))
+		for i, n := range v {
+			ns[i] = n.String()
+		}
+		return &types.AttributeValueMemberNS{Value: ns}, nil
+	case [][]byte:
+		return &types.AttributeValueMemberBS{Value: v}, nil
+	case []any:
+		l := make([]types.AttributeValue, len(v))
+		for i, e := range v {
+			av, err := fromPlainValue(e)
+			if err != nil {
+				return nil, err
+			}
+			l[i] = av
+		}
+		return &types.AttributeValueMemberL{Value: l}, nil
+	case map[string]any:
+		m, err := FromPlainMap(v)
+		if err != nil {
+			return nil, err
+		}
+		return &types.AttributeValueMemberM{Value: m}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T", v)
+	}
+}
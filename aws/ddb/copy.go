@@ -0,0 +1,135 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CopyItems scans srcTable, optionally narrowed by a filter expression, and writes every item into dstTable returning the number of items copied.
+// items are streamed through the scan iterator and written 25 at a time so huge tables don't exhaust memory
+func (d *DynamoDB) CopyItems(ctx context.Context, srcTable, dstTable string, filter *expression.Expression) (int, error) {
+	return d.CopyItemsFunc(ctx, srcTable, dstTable, filter, nil)
+}
+
+// CopyItemsFunc is like CopyItems but passes each item through transform before it is written, letting callers remap attributes during the copy.
+// returning a nil item from transform skips it and returning an error stops the copy
+func (d *DynamoDB) CopyItemsFunc(ctx context.Context, srcTable, dstTable string, filter *expression.Expression, transform func(map[string]types.AttributeValue) (map[string]types.AttributeValue, error)) (_ int, err error) {
+	ctx, span := d.startSpan(ctx, "CopyItems", aws.String(srcTable))
+	defer func() { endSpan(span, err) }()
+
+	input := &dynamodb.ScanInput{
+		TableName: aws.String(srcTable),
+	}
+	if filter != nil {
+		input.FilterExpression = filter.Filter()
+		input.ExpressionAttributeNames = filter.Names()
+		input.ExpressionAttributeValues = filter.Values()
+	}
+
+	it := d.NewScanIterator(input)
+	bw := d.newBatchWriter(dstTable)
+
+	for it.Next(ctx) {
+		item := it.Item()
+
+		if transform != nil {
+			var err error
+			item, err = transform(item)
+			if err != nil {
+				return bw.written, fmt.Errorf("error transforming item: %w", err)
+			}
+			if item == nil {
+				continue
+			}
+		}
+
+		if err := bw.add(ctx, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}}); err != nil {
+			return bw.written, fmt.Errorf("error writing items: %w", err)
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		return bw.written, fmt.Errorf("error scanning dynamo: %w", err)
+	}
+
+	if err := bw.flush(ctx); err != nil {
+		return bw.written, fmt.Errorf("error writing items: %w", err)
+	}
+
+	return bw.written, nil
+}
+
+// MigrateItems scans the table, passes every item through transform and writes the result back in place, returning the number of items rewritten.
+// items for which transform returns nil or an unchanged map are skipped so re-running a migration only rewrites what still needs it, an error from transform halts the migration
+func (d *DynamoDB) MigrateItems(ctx context.Context, tableName string, transform func(map[string]types.AttributeValue) (map[string]types.AttributeValue, error)) (int, error) {
+	return d.CopyItemsFunc(ctx, tableName, tableName, nil, func(item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+		// transform may edit the item in place, down to nested maps and lists, keep a deep copy to compare against
+		before := cloneItem(item)
+
+		after, err := transform(item)
+		if err != nil {
+			return nil, err
+		}
+
+		if after == nil || reflect.DeepEqual(before, after) {
+			return nil, nil
+		}
+
+		return after, nil
+	})
+}
+
+// cloneItem deep copies an item, so edits to any attribute of the copy, nested or not, leave the original untouched
+func cloneItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	out := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		out[k] = cloneValue(v)
+	}
+	return out
+}
+
+// cloneValue deep copies a single attribute value
+func cloneValue(v types.AttributeValue) types.AttributeValue {
+	switch v := v.(type) {
+	case *types.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: v.Value}
+	case *types.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: v.Value}
+	case *types.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: slices.Clone(v.Value)}
+	case *types.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: v.Value}
+	case *types.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: v.Value}
+	case *types.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: slices.Clone(v.Value)}
+	case *types.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: slices.Clone(v.Value)}
+	case *types.AttributeValueMemberBS:
+		bs := make([][]byte, len(v.Value))
+		for i, b := range v.Value {
+			bs[i] = slices.Clone(b)
+		}
+		return &types.AttributeValueMemberBS{Value: bs}
+	case *types.AttributeValueMemberL:
+		l := make([]types.AttributeValue, len(v.Value))
+		for i, e := range v.Value {
+			l[i] = cloneValue(e)
+		}
+		return &types.AttributeValueMemberL{Value: l}
+	case *types.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: cloneItem(v.Value)}
+	default:
+		return v
+	}
+}
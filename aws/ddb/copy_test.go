@@ -0,0 +1,43 @@
+package ddb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func TestMigrateItemsNestedInPlaceEdit(t *testing.T) {
+	d, f := newFake(t)
+	for _, sk := range []string{"1", "2"} {
+		item := key("a", sk)
+		item["meta"] = &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"v": str("1")}}
+		if _, err := f.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("t"), Item: item}); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+	}
+
+	// the transform bumps the nested version in place and returns the same map, only for the first item
+	n, err := d.MigrateItems(context.Background(), "t", func(item map[string]types.AttributeValue) (map[string]types.AttributeValue, error) {
+		if item["sk"].(*types.AttributeValueMemberS).Value == "1" {
+			item["meta"].(*types.AttributeValueMemberM).Value["v"] = str("2")
+		}
+		return item, nil
+	})
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected only the edited item to be rewritten, got %d", n)
+	}
+
+	got, err := d.GetOne(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("t"), Key: key("a", "1")})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if v := got["meta"].(*types.AttributeValueMemberM).Value["v"].(*types.AttributeValueMemberS).Value; v != "2" {
+		t.Fatalf("expected the nested edit to be written, got %q", v)
+	}
+}
@@ -0,0 +1,60 @@
+package ddb
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CountTable returns the exact number of items in the table by paging a Select COUNT scan to completion.
+// a count scan still reads every item, so it consumes read capacity proportional to the size of the table, use EstimateTableCount when an approximate number is enough
+func (d *DynamoDB) CountTable(ctx context.Context, tableName string) (int, error) {
+	return d.scanCount(ctx, &dynamodb.ScanInput{
+		TableName: aws.String(tableName),
+	})
+}
+
+// EstimateTableCount returns the ItemCount dynamo reports for the table through DescribeTable.
+// it costs no read capacity but dynamo only refreshes the number roughly every six hours, so it can be stale
+func (d *DynamoDB) EstimateTableCount(ctx context.Context, tableName string) (int, error) {
+	output, err := d.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		return 0, mapTableErr(err)
+	}
+
+	return int(aws.ToInt64(output.Table.ItemCount)), nil
+}
+
+// scanCount pages a Select COUNT scan until the end of the table and sums the count of every page
+func (d *DynamoDB) scanCount(ctx context.Context, input *dynamodb.ScanInput) (int, error) {
+	input.Select = types.SelectCount
+
+	var count, scanned int
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		input.ExclusiveStartKey = lastEvaluatedKey
+		output, err := call(ctx, d, "Scan", input.TableName, input, d.reader().Scan)
+		if err != nil {
+			return 0, err
+		}
+		count += int(output.Count)
+		if output.LastEvaluatedKey == nil {
+			break
+		}
+		scanned += int(output.ScannedCount)
+		if err := d.checkScanBudget(scanned); err != nil {
+			return count, err
+		}
+		lastEvaluatedKey = output.LastEvaluatedKey
+	}
+
+	return count, nil
+}
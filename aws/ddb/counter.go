@@ -0,0 +1,76 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Increment atomically adds delta to the number attribute attr and returns its new value, a missing attribute starts at 0
+func (d *DynamoDB) Increment(ctx context.Context, tableName string, key map[string]types.AttributeValue, attr string, delta int64) (int64, error) {
+	return d.addToCounter(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(tableName),
+		Key:                       key,
+		UpdateExpression:          aws.String("ADD #a :d"),
+		ExpressionAttributeNames:  map[string]string{"#a": attr},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":d": numberValue(delta)},
+	}, attr)
+}
+
+// Decrement atomically subtracts delta from the number attribute attr and returns its new value, a missing attribute starts at 0
+func (d *DynamoDB) Decrement(ctx context.Context, tableName string, key map[string]types.AttributeValue, attr string, delta int64) (int64, error) {
+	return d.Increment(ctx, tableName, key, attr, -delta)
+}
+
+// IncrementIfBelow is like Increment but only applies when the new value stays at or under limit, returning ErrConditionFailed when it would exceed it.
+// the cap is enforced server-side in the same call so concurrent increments can't overshoot it, a missing attribute starts at 0 and only passes when delta alone stays under limit
+func (d *DynamoDB) IncrementIfBelow(ctx context.Context, tableName string, key map[string]types.AttributeValue, attr string, delta, limit int64) (int64, error) {
+	cond := "attribute_not_exists(#a) OR #a <= :limit"
+	if delta > limit {
+		// only an existing counter low enough, e.g. a negative one, can take delta
+		cond = "#a <= :limit"
+	}
+
+	return d.addToCounter(ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String(tableName),
+		Key:                      key,
+		UpdateExpression:         aws.String("ADD #a :d"),
+		ConditionExpression:      aws.String(cond),
+		ExpressionAttributeNames: map[string]string{"#a": attr},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":d":     numberValue(delta),
+			":limit": numberValue(limit - delta),
+		},
+	}, attr)
+}
+
+// addToCounter runs the counter update and parses the new value of attr out of the UPDATED_NEW attributes
+func (d *DynamoDB) addToCounter(ctx context.Context, input *dynamodb.UpdateItemInput, attr string) (int64, error) {
+	input.ReturnValues = types.ReturnValueUpdatedNew
+
+	output, err := d.UpdateItem(ctx, input)
+	if err != nil {
+		return 0, mapConditionErr(err)
+	}
+
+	n, ok := output.Attributes[attr].(*types.AttributeValueMemberN)
+	if !ok {
+		// dry run skips the update and returns no attributes
+		return 0, nil
+	}
+
+	v, err := strconv.ParseInt(n.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing counter %q: %w", attr, err)
+	}
+
+	return v, nil
+}
+
+func numberValue(n int64) *types.AttributeValueMemberN {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
@@ -0,0 +1,38 @@
+package ddb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+)
+
+func TestIncrementIfBelow(t *testing.T) {
+	d, _ := newFake(t)
+	ctx := context.Background()
+
+	// a missing counter starts at 0, a delta above the limit can't apply to it
+	if _, err := d.IncrementIfBelow(ctx, "t", key("a", "1"), "c", 5, 3); !errors.Is(err, ddb.ErrConditionFailed) {
+		t.Fatalf("expected ErrConditionFailed on a missing counter, got %v", err)
+	}
+
+	if _, err := d.Increment(ctx, "t", key("a", "1"), "c", -10); err != nil {
+		t.Fatalf("increment: %v", err)
+	}
+
+	// -10 + 5 stays under 3 even though the delta alone doesn't
+	got, err := d.IncrementIfBelow(ctx, "t", key("a", "1"), "c", 5, 3)
+	if err != nil || got != -5 {
+		t.Fatalf("expected -5, got %d, %v", got, err)
+	}
+
+	got, err = d.IncrementIfBelow(ctx, "t", key("a", "1"), "c", 8, 3)
+	if err != nil || got != 3 {
+		t.Fatalf("expected to reach the limit of 3, got %d, %v", got, err)
+	}
+
+	if _, err := d.IncrementIfBelow(ctx, "t", key("a", "1"), "c", 1, 3); !errors.Is(err, ddb.ErrConditionFailed) {
+		t.Fatalf("expected ErrConditionFailed above the limit, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package ddb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Page is a single page of a cursor paginated query
+type Page[T any] struct {
+	Items []T
+	// NextToken resumes the query after the last item of the page, empty when dynamo reported no more items
+	NextToken string
+	// PrevToken returns the window right before the first item of the page, empty on the first page
+	PrevToken string
+}
+
+// cursor is the decoded form of a pagination token
+type cursor struct {
+	Key map[string]cursorAttr `json:"k"`
+	// Reverse marks a token that walks backward from Key
+	Reverse bool `json:"r,omitempty"`
+}
+
+// cursorAttr is the JSON form of a key attribute inside a pagination token, keys can only be strings, numbers or binary
+type cursorAttr struct {
+	S *string `json:"S,omitempty"`
+	N *string `json:"N,omitempty"`
+	B []byte  `json:"B,omitempty"`
+}
+
+// QueryPageAs runs a single page of the query starting after token and unmarshals its items into T.
+// the returned nextToken is an opaque string encoding LastEvaluatedKey, it is empty on the last page and an empty token starts from the beginning
+func QueryPageAs[T any](ctx context.Context, d *DynamoDB, input *dynamodb.QueryInput, token string) (items []T, nextToken string, err error) {
+	page, err := QueryPageBidirectional[T](ctx, d, input, token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return page.Items, page.NextToken, nil
+}
+
+// QueryPageBidirectional runs a single page of the query at token and returns it with tokens for both the next and the previous window, input.Limit is the page size.
+// dynamo can only page forward, so a PrevToken walks backward by re-running the query with ScanIndexForward flipped from the first item of the page and reverses the results back into the original order.
+// like forward paging, where dynamo can hand out a NextToken that leads to an empty page, a PrevToken on the page right after the first one can lead to an empty page, restart with an empty token then
+func QueryPageBidirectional[T any](ctx context.Context, d *DynamoDB, input *dynamodb.QueryInput, token string) (*Page[T], error) {
+	cur, err := d.decodeToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	in := *input
+	in.ExclusiveStartKey = nil
+	if cur != nil {
+		in.ExclusiveStartKey = cur.key()
+		if cur.Reverse {
+			forward := input.ScanIndexForward == nil || *input.ScanIndexForward
+			in.ScanIndexForward = aws.Bool(!forward)
+		}
+	}
+
+	output, err := call(ctx, d, "Query", in.TableName, &in, d.reader().Query)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := output.Items
+	if cur != nil && cur.Reverse {
+		raw = slices.Clone(raw)
+		slices.Reverse(raw)
+	}
+
+	items, err := unmarshalItems[T](d, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Page[T]{Items: items}
+
+	switch {
+	case cur == nil:
+		// first page, there is nothing before it
+		page.NextToken, err = d.encodeToken(output.LastEvaluatedKey, false)
+	case cur.Reverse:
+		if len(raw) > 0 {
+			page.NextToken, err = d.encodeToken(keyOf(raw[len(raw)-1], cur.Key), false)
+			if err == nil && output.LastEvaluatedKey != nil {
+				page.PrevToken, err = d.encodeToken(keyOf(raw[0], cur.Key), true)
+			}
+		}
+	default:
+		page.NextToken, err = d.encodeToken(output.LastEvaluatedKey, false)
+		if err == nil && len(raw) > 0 {
+			page.PrevToken, err = d.encodeToken(keyOf(raw[0], cur.Key), true)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// keyOf extracts from item the key attributes named in ref
+func keyOf(item map[string]types.AttributeValue, ref map[string]cursorAttr) map[string]types.AttributeValue {
+	key := make(map[string]types.AttributeValue, len(ref))
+	for name := range ref {
+		if av, ok := item[name]; ok {
+			key[name] = av
+		}
+	}
+	return key
+}
+
+// key converts the cursor back into a dynamo key
+func (c *cursor) key() map[string]types.AttributeValue {
+	key := make(map[string]types.AttributeValue, len(c.Key))
+	for name, a := range c.Key {
+		switch {
+		case a.S != nil:
+			key[name] = &types.AttributeValueMemberS{Value: *a.S}
+		case a.N != nil:
+			key[name] = &types.AttributeValueMemberN{Value: *a.N}
+		default:
+			key[name] = &types.AttributeValueMemberB{Value: a.B}
+		}
+	}
+	return key
+}
+
+// encodeToken turns a key into an opaque url safe token, sealed with the WithTokenKey key when one is set. a nil key gives an empty token
+func (d *DynamoDB) encodeToken(key map[string]types.AttributeValue, reverse bool) (string, error) {
+	if key == nil {
+		return "", nil
+	}
+
+	cur := cursor{Key: make(map[string]cursorAttr, len(key)), Reverse: reverse}
+	for name, av := range key {
+		switch v := av.(type) {
+		case *types.AttributeValueMemberS:
+			cur.Key[name] = cursorAttr{S: &v.Value}
+		case *types.AttributeValueMemberN:
+			cur.Key[name] = cursorAttr{N: &v.Value}
+		case *types.AttributeValueMemberB:
+			cur.Key[name] = cursorAttr{B: v.Value}
+		default:
+			return "", fmt.Errorf("unsupported key attribute type %T for %q", av, name)
+		}
+	}
+
+	b, err := json.Marshal(cur)
+	if err != nil {
+		return "", fmt.Errorf("error encoding token: %w", err)
+	}
+
+	if d.tokenAEAD != nil {
+		b, err = sealToken(d.tokenAEAD, b)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeToken is the inverse of encodeToken, an empty token gives a nil cursor and a malformed or tampered one ErrInvalidToken
+func (d *DynamoDB) decodeToken(token string) (*cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if d.tokenAEAD != nil {
+		b, err = openToken(d.tokenAEAD, b)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var cur cursor
+	if err := json.Unmarshal(b, &cur); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	if len(cur.Key) == 0 {
+		return nil, fmt.Errorf("%w: missing key", ErrInvalidToken)
+	}
+	for name, a := range cur.Key {
+		if a.S == nil && a.N == nil && a.B == nil {
+			return nil, fmt.Errorf("%w: attribute %q has no value", ErrInvalidToken, name)
+		}
+	}
+
+	return &cur, nil
+}
@@ -0,0 +1,157 @@
+package ddb_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+)
+
+type row struct {
+	PK string `dynamodbav:"pk"`
+	SK string `dynamodbav:"sk"`
+}
+
+// queryInput queries every item of the pk partition of the "t" table, limit items per page
+func queryInput(pk string, limit int32) *dynamodb.QueryInput {
+	return &dynamodb.QueryInput{
+		TableName:                 aws.String("t"),
+		KeyConditionExpression:    aws.String("#pk = :pk"),
+		ExpressionAttributeNames:  map[string]string{"#pk": "pk"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":pk": &types.AttributeValueMemberS{Value: pk}},
+		Limit:                     aws.Int32(limit),
+	}
+}
+
+func sortKeys(rows []row) []string {
+	sks := make([]string, len(rows))
+	for i, r := range rows {
+		sks[i] = r.SK
+	}
+	return sks
+}
+
+func TestQueryPageAsTokenRoundTrip(t *testing.T) {
+	for name, opts := range map[string][]ddb.Option{
+		"plain":  nil,
+		"sealed": {ddb.WithTokenKey([]byte("secret"))},
+	} {
+		t.Run(name, func(t *testing.T) {
+			d, f := newFake(t, opts...)
+			putItems(t, f, "a", "1", "2", "3", "4", "5")
+
+			var got []string
+			token := ""
+			for pages := 0; ; pages++ {
+				if pages > 5 {
+					t.Fatalf("paging didn't stop, got %v", got)
+				}
+
+				items, next, err := ddb.QueryPageAs[row](context.Background(), d, queryInput("a", 2), token)
+				if err != nil {
+					t.Fatalf("page %d: %v", pages, err)
+				}
+				got = append(got, sortKeys(items)...)
+
+				if next == "" {
+					break
+				}
+				token = next
+			}
+
+			if want := []string{"1", "2", "3", "4", "5"}; !slices.Equal(got, want) {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestQueryPageAsRejectsTamperedToken(t *testing.T) {
+	d, f := newFake(t, ddb.WithTokenKey([]byte("secret")))
+	putItems(t, f, "a", "1", "2", "3")
+
+	_, next, err := ddb.QueryPageAs[row](context.Background(), d, queryInput("a", 1), "")
+	if err != nil || next == "" {
+		t.Fatalf("expected a next token, got %q, %v", next, err)
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(next)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	b[len(b)-1] ^= 1
+	tampered := base64.RawURLEncoding.EncodeToString(b)
+
+	for name, token := range map[string]string{
+		"tampered":   tampered,
+		"not base64": "!!",
+		"not json":   base64.RawURLEncoding.EncodeToString([]byte("nope")),
+	} {
+		if _, _, err := ddb.QueryPageAs[row](context.Background(), d, queryInput("a", 1), token); !errors.Is(err, ddb.ErrInvalidToken) {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+
+	// an unsealed token must not be accepted by a wrapper that seals them
+	plain, pf := newFake(t)
+	putItems(t, pf, "a", "1", "2", "3")
+	_, unsealed, err := ddb.QueryPageAs[row](context.Background(), plain, queryInput("a", 1), "")
+	if err != nil {
+		t.Fatalf("unsealed page: %v", err)
+	}
+	if _, _, err := ddb.QueryPageAs[row](context.Background(), d, queryInput("a", 1), unsealed); !errors.Is(err, ddb.ErrInvalidToken) {
+		t.Fatalf("expected ErrInvalidToken for an unsealed token, got %v", err)
+	}
+}
+
+func TestQueryPageBidirectionalNextNextPrev(t *testing.T) {
+	for name, forward := range map[string]bool{"ascending": true, "descending": false} {
+		t.Run(name, func(t *testing.T) {
+			d, f := newFake(t)
+			putItems(t, f, "a", "1", "2", "3", "4", "5", "6", "7", "8", "9")
+
+			input := queryInput("a", 3)
+			input.ScanIndexForward = aws.Bool(forward)
+
+			page := func(token string) *ddb.Page[row] {
+				t.Helper()
+				p, err := ddb.QueryPageBidirectional[row](context.Background(), d, input, token)
+				if err != nil {
+					t.Fatalf("page: %v", err)
+				}
+				return p
+			}
+
+			first := page("")
+			second := page(first.NextToken)
+			third := page(second.NextToken)
+			back := page(third.PrevToken)
+
+			if !slices.Equal(sortKeys(back.Items), sortKeys(second.Items)) {
+				t.Fatalf("expected Prev from page 3 to return page 2 %v, got %v", sortKeys(second.Items), sortKeys(back.Items))
+			}
+
+			want := []string{"4", "5", "6"}
+			if !forward {
+				want = []string{"6", "5", "4"}
+			}
+			if !slices.Equal(sortKeys(back.Items), want) {
+				t.Fatalf("expected %v, got %v", want, sortKeys(back.Items))
+			}
+
+			// the tokens of the page reached backward keep walking in both directions
+			if again := page(back.NextToken); !slices.Equal(sortKeys(again.Items), sortKeys(third.Items)) {
+				t.Fatalf("expected Next from the Prev page to return page 3 %v, got %v", sortKeys(third.Items), sortKeys(again.Items))
+			}
+			if start := page(back.PrevToken); !slices.Equal(sortKeys(start.Items), sortKeys(first.Items)) {
+				t.Fatalf("expected Prev from the Prev page to return page 1 %v, got %v", sortKeys(first.Items), sortKeys(start.Items))
+			}
+		})
+	}
+}
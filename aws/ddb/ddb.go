@@ -2,81 +2,144 @@ package ddb
 
 import (
 	"context"
+	"crypto/cipher"
 	"errors"
 	"fmt"
+	"log/slog"
 	"slices"
 	"sync"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-	"github.com/jap1998/aws-code-snippets/aws/configuration"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
 )
 
 var (
-	ErrNotFound = errors.New("Item not found")
+	ErrNotFound              = errors.New("Item not found")
+	ErrConsistentReadOnIndex = errors.New("consistent reads are not supported on global secondary indexes")
+	ErrConditionFailed       = errors.New("condition check failed")
+	ErrTableNotFound         = errors.New("table not found")
+	ErrInvalidToken          = errors.New("invalid pagination token")
+	ErrCircuitOpen           = errors.New("circuit breaker is open")
+	ErrSchemaMismatch        = errors.New("table key schema mismatch")
+	ErrBackupNotFound        = errors.New("backup not found")
+	ErrTableAlreadyExists    = errors.New("table already exists")
+	ErrBackfillTimeout       = errors.New("timed out waiting for index backfill")
+	ErrPayPerRequest         = errors.New("table is in PAY_PER_REQUEST billing mode")
+	ErrUnknownEntityType     = errors.New("unknown entity type")
+	ErrBudgetExceeded        = errors.New("scan budget exceeded")
+	ErrAttributeNotProjected = errors.New("attribute not projected into index")
+	ErrTableNotIdle          = errors.New("timed out waiting for table to become idle")
+	ErrItemNotVisible        = errors.New("timed out waiting for item to appear")
+	ErrRetryBudgetExhausted  = errors.New("retry budget exhausted")
+	ErrInvalidItemKey        = errors.New("invalid item key")
+	ErrIndexNotFound         = errors.New("index not found")
 )
 
 type DynamoDB struct {
-	client *dynamodb.Client
+	client         DynamoAPI
+	clientOptFns   []func(*dynamodb.Options)
+	writeLimiter   *rate.Limiter
+	dryRun         *dryRunLog
+	tracer         trace.Tracer
+	logger         *slog.Logger
+	defaultTimeout time.Duration
+	autoCreate     *autoCreate
+	tokenAEAD      cipher.AEAD
+	breaker        *circuitBreaker
+	adaptive       *adaptiveThrottle
+	scanBudget     int
+	cacheIndexes   bool
+	consistent     bool
+	keySchemas     keySchemaCache
+	readRegion     string
+	readClient     DynamoAPI
+	metrics        Metrics
+	decoderOpts    []func(*attributevalue.DecoderOptions)
+	encoderOpts    []func(*attributevalue.EncoderOptions)
+	maxConcurrency int
+	derivedKeys    func(map[string]types.AttributeValue) map[string]types.AttributeValue
 }
 
 // MustGetClient inits a new client with default options if option Fns are not provided otherwise it uses the defaults, if an error occurs it panics.
 func MustGetClient(ctx context.Context, optFns ...func(*dynamodb.Options)) *DynamoDB {
-	c := configuration.MustGetConfig(ctx)
-	client := dynamodb.NewFromConfig(c, optFns...)
-
-	return &DynamoDB{client: client}
+	return MustNew(ctx, WithClientOptions(optFns...))
 }
 
 // GetItem is a wrapper around dynamodb.GetItem with an already initialized client
 func (d *DynamoDB) GetItem(ctx context.Context, input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
-	return d.client.GetItem(ctx, input)
+	return call(ctx, d, "GetItem", input.TableName, input, d.reader().GetItem)
 }
 
 // PutItem is a wrapper around dynamodb.PutItem with an already initialized client
 func (d *DynamoDB) PutItem(ctx context.Context, input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
-	return d.client.PutItem(ctx, input)
+	if d.derivedKeys != nil {
+		in := *input
+		in.Item = d.withDerivedKeys(input.Item)
+		input = &in
+	}
+	if d.recordDryRun("PutItem", input.TableName, 1, input) {
+		return &dynamodb.PutItemOutput{}, nil
+	}
+	if err := d.waitWrite(ctx, writeUnits(input.Item)); err != nil {
+		return nil, err
+	}
+	return call(ctx, d, "PutItem", input.TableName, input, d.client.PutItem)
 }
 
 // UpdateItem is a wrapper around dynamodb.UpdateItem with an already initialized client
 func (d *DynamoDB) UpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
-	return d.client.UpdateItem(ctx, input)
+	if d.recordDryRun("UpdateItem", input.TableName, 1, input) {
+		return &dynamodb.UpdateItemOutput{}, nil
+	}
+	return call(ctx, d, "UpdateItem", input.TableName, input, d.client.UpdateItem)
 }
 
 // DeleteItem is a wrapper around dynamodb.DeleteItem with an already initialized client
 func (d *DynamoDB) DeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
-	return d.client.DeleteItem(ctx, input)
+	if d.recordDryRun("DeleteItem", input.TableName, 1, input) {
+		return &dynamodb.DeleteItemOutput{}, nil
+	}
+	return call(ctx, d, "DeleteItem", input.TableName, input, d.client.DeleteItem)
 }
 
 // Query is a wrapper around dynamodb.Query with an already initialized client
 func (d *DynamoDB) Query(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
-	return d.client.Query(ctx, input)
+	return call(ctx, d, "Query", input.TableName, input, d.reader().Query)
 }
 
 // Scan is a wrapper around dynamodb.Scan with an already initialized client
 func (d *DynamoDB) Scan(ctx context.Context, input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
-	return d.client.Scan(ctx, input)
+	return call(ctx, d, "Scan", input.TableName, input, d.reader().Scan)
 }
 
 // -- custom -- //
 // UpdateIfExistsOrFail is a wrapper around dynamodb.UpdateItem with an already initialized client that updates an item if it exists or returns an error if it doesn't
+// the existence check is an attribute_exists condition on the key evaluated by dynamo in the same call, so the item can't be deleted between the check and the update.
+// any condition already on the input is kept, if it doesn't hold ErrNotFound is returned as well. like UpdateIf the input is not modified
 func (d *DynamoDB) UpdateIfExistsOrFail(ctx context.Context, input *dynamodb.UpdateItemInput) error {
-	// Check if item exists
-	_, err := d.GetOne(ctx, &dynamodb.GetItemInput{
-		TableName: input.TableName,
-		Key:       input.Key,
-	})
-
-	if err != nil {
-		if errors.Is(err, ErrNotFound) {
-			return ErrNotFound
+	var keyName string
+	for k := range input.Key {
+		if keyName == "" || k < keyName {
+			keyName = k
 		}
-		return err
 	}
 
-	_, err = d.client.UpdateItem(ctx, input)
+	if keyName == "" {
+		return errors.New("update input has no key")
+	}
+
+	err := d.UpdateIf(ctx, input, expression.AttributeExists(expression.NameNoDotSplit(keyName)))
 
 	if err != nil {
+		if errors.Is(err, ErrConditionFailed) {
+			return ErrNotFound
+		}
 		return err
 	}
 
@@ -84,8 +147,9 @@ func (d *DynamoDB) UpdateIfExistsOrFail(ctx context.Context, input *dynamodb.Upd
 }
 
 // GetOne is a wrapper around dynamodb.GetItem with an already initialized client that gets the first item that matches the provided key or error ErrNotFound if no item is found
+// an item that exists but has none of the projected attributes comes back as an empty non-nil map, use GetOneExists to get that distinction as an explicit flag
 func (d *DynamoDB) GetOne(ctx context.Context, input *dynamodb.GetItemInput) (item map[string]types.AttributeValue, err error) {
-	output, err := d.client.GetItem(ctx, input)
+	output, err := call(ctx, d, "GetItem", input.TableName, input, d.reader().GetItem)
 
 	if err != nil {
 		return nil, err
@@ -98,44 +162,100 @@ func (d *DynamoDB) GetOne(ctx context.Context, input *dynamodb.GetItemInput) (it
 	return output.Item, nil
 }
 
+// GetOneExists is like GetOne but reports whether the item exists with found instead of ErrNotFound.
+// with a ProjectionExpression naming only attributes the item doesn't have dynamo sends back an empty item, GetOneExists returns it as an empty map with found true so it can't be mistaken for a missing item
+func (d *DynamoDB) GetOneExists(ctx context.Context, input *dynamodb.GetItemInput) (item map[string]types.AttributeValue, found bool, err error) {
+	output, err := call(ctx, d, "GetItem", input.TableName, input, d.reader().GetItem)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if output.Item == nil {
+		return nil, false, nil
+	}
+
+	return output.Item, true, nil
+}
+
 // ScanAll is a wrapper around dynamodb.Scan that takes keeps fetching dynamo until it retrieves all items with the provided query
-func (d *DynamoDB) ScanAll(ctx context.Context, input *dynamodb.ScanInput) ([]map[string]types.AttributeValue, error) {
+func (d *DynamoDB) ScanAll(ctx context.Context, input *dynamodb.ScanInput) (_ []map[string]types.AttributeValue, err error) {
+	ctx, span := d.startSpan(ctx, "ScanAll", input.TableName)
+	defer func() { endSpan(span, err) }()
+
+	items, _, _, err := d.scanAll(ctx, input)
+	return items, err
+}
+
+// ScanAllWithCounts is like ScanAll but also returns the Count and ScannedCount of every page summed up, the items that passed the filter against the items dynamo read.
+// a scanned count much larger than the returned one means the filter discards most of what the scan pays for
+func (d *DynamoDB) ScanAllWithCounts(ctx context.Context, input *dynamodb.ScanInput) (items []map[string]types.AttributeValue, returned int, scanned int, err error) {
+	ctx, span := d.startSpan(ctx, "ScanAllWithCounts", input.TableName)
+	defer func() { endSpan(span, err) }()
+
+	return d.scanAll(ctx, input)
+}
+
+// scanAll pages the scan to the end within the scan budget, summing the Count and ScannedCount of the pages
+func (d *DynamoDB) scanAll(ctx context.Context, input *dynamodb.ScanInput) ([]map[string]types.AttributeValue, int, int, error) {
 	items := make([]map[string]types.AttributeValue, 0)
 	var lastEvaluatedKey map[string]types.AttributeValue
+	var returned, scanned int
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, returned, scanned, err
+		}
 		input.ExclusiveStartKey = lastEvaluatedKey
-		output, err := d.client.Scan(ctx, input)
+		output, err := call(ctx, d, "Scan", input.TableName, input, d.reader().Scan)
 		if err != nil {
-			return nil, err
+			return nil, returned, scanned, err
 		}
 		items = append(items, output.Items...)
+		returned += int(output.Count)
+		scanned += int(output.ScannedCount)
 		if output.LastEvaluatedKey == nil {
 			break
 		}
+		if err := d.checkScanBudget(scanned); err != nil {
+			return items, returned, scanned, err
+		}
 		lastEvaluatedKey = output.LastEvaluatedKey
 	}
 
-	return items, nil
+	return items, returned, scanned, nil
 }
 
 // QueryWithPagination is a wrapper around dynamodb.Query that takes pagination options and returns a PaginatedResults struct
-// pagination limits and queryInput.Limit are not the same, the former is the maximum number of items to return and the latter is the maximum number of items to return per page
-func (d *DynamoDB) QueryWithPagination(ctx context.Context, input *PaginationOps) (*PaginatedResults, error) {
+// the results are the input.Skip / input.Limit window of the matching items, see PaginationOps. pagination limits and queryInput.Limit are not the same, the former is the page size and the latter is the maximum number of items dynamo evaluates per call.
+// breaking change: Limit used to be where the window ended, returning items [Skip, Limit), callers relying on that have to pass the page size as Limit now, e.g. Limit - Skip
+func (d *DynamoDB) QueryWithPagination(ctx context.Context, input *PaginationOps) (_ *PaginatedResults, err error) {
+	ctx, span := d.startSpan(ctx, "QueryWithPagination", input.TableName)
+	defer func() { endSpan(span, err) }()
+
 	var wg sync.WaitGroup
 	var items = make([]map[string]types.AttributeValue, 0)
 	var count int
 	var errChan = make(chan error)
 
+	// both goroutines page from the start on their own copy of the query
+	query := input.QueryInput
+	query.ExclusiveStartKey = nil
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 
+		in := query
 		var lastEvaluatedKey map[string]types.AttributeValue
+		end := input.Skip + input.Limit
 
 		for {
-			input.ExclusiveStartKey = lastEvaluatedKey
-			output, err := d.client.Query(ctx, &input.QueryInput)
+			if err := ctx.Err(); err != nil {
+				errChan <- err
+				return
+			}
+			in.ExclusiveStartKey = lastEvaluatedKey
+			output, err := call(ctx, d, "Query", in.TableName, &in, d.reader().Query)
 
 			if err != nil {
 				errChan <- fmt.Errorf("error querying dynamo: %w", err)
@@ -143,15 +263,15 @@ func (d *DynamoDB) QueryWithPagination(ctx context.Context, input *PaginationOps
 			}
 
 			var l int
-			if len(output.Items)+len(items) > input.Limit {
-				l = input.Limit - len(items)
+			if len(output.Items)+len(items) > end {
+				l = end - len(items)
 			} else {
 				l = len(output.Items)
 			}
 
 			items = append(items, output.Items[:l]...)
 
-			if output.LastEvaluatedKey == nil || len(items) >= input.Limit {
+			if output.LastEvaluatedKey == nil || len(items) >= end {
 				break
 			}
 
@@ -167,20 +287,24 @@ func (d *DynamoDB) QueryWithPagination(ctx context.Context, input *PaginationOps
 		errChan <- nil
 	}()
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		c, err := d.GetQueryCount(ctx, input.QueryInput)
+	if input.SkipCount {
+		count = -1
+	} else {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := d.GetQueryCount(ctx, query)
 
-		if err != nil {
-			errChan <- fmt.Errorf("error getting query count: %w", err)
-			return
-		}
+			if err != nil {
+				errChan <- fmt.Errorf("error getting query count: %w", err)
+				return
+			}
 
-		count = c
+			count = c
 
-		errChan <- nil
-	}()
+			errChan <- nil
+		}()
+	}
 
 	go func() {
 		wg.Wait()
@@ -198,46 +322,130 @@ func (d *DynamoDB) QueryWithPagination(ctx context.Context, input *PaginationOps
 		return nil, errors.Join(errs...)
 	}
 
+	totalPages, page := pageNumbers(input.Skip, input.Limit, count)
+
 	return &PaginatedResults{
-		Items: items,
-		Skip:  input.Skip,
-		Limit: input.Limit,
-		Count: count,
+		Items:      items,
+		Skip:       input.Skip,
+		Limit:      input.Limit,
+		Count:      count,
+		TotalPages: totalPages,
+		Page:       page,
 	}, nil
 }
 
 // QueryAll is a wrapper around dynamodb.Query that takes keeps fetching dynamo until it retrieves all items with the provided query
-func (d *DynamoDB) QueryAll(ctx context.Context, input *dynamodb.QueryInput) ([]map[string]types.AttributeValue, error) {
+func (d *DynamoDB) QueryAll(ctx context.Context, input *dynamodb.QueryInput) (_ []map[string]types.AttributeValue, err error) {
+	ctx, span := d.startSpan(ctx, "QueryAll", input.TableName)
+	defer func() { endSpan(span, err) }()
+
+	items, _, _, err := d.queryAll(ctx, input, nil)
+	return items, err
+}
+
+// QueryAllDebug is like QueryAll but also returns how many pages were fetched, along with WithLogger it shows where a query stops early or how many round trips a capacity spike took.
+// pages counts the pages fetched before an error too
+func (d *DynamoDB) QueryAllDebug(ctx context.Context, input *dynamodb.QueryInput) (items []map[string]types.AttributeValue, pages int, err error) {
+	ctx, span := d.startSpan(ctx, "QueryAllDebug", input.TableName)
+	defer func() { endSpan(span, err) }()
+
+	items, pages, _, err = d.queryAll(ctx, input, nil)
+	return items, pages, err
+}
 
+// QueryAllWithin is like QueryAll but stops paging once budget has elapsed, returning the items read so far with truncated set when the query had more pages.
+// the budget is checked between pages so a page already in flight is always finished and the first page is always read, use a context deadline to bound the calls themselves
+func (d *DynamoDB) QueryAllWithin(ctx context.Context, input *dynamodb.QueryInput, budget time.Duration) (items []map[string]types.AttributeValue, truncated bool, err error) {
+	ctx, span := d.startSpan(ctx, "QueryAllWithin", input.TableName)
+	defer func() { endSpan(span, err) }()
+
+	start := time.Now()
+	items, _, truncated, err = d.queryAll(ctx, input, func() bool {
+		return time.Since(start) >= budget
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return items, truncated, nil
+}
+
+// queryAll pages the query to the end, counting the pages fetched. stop, when not nil, is checked after every page that has a next one
+// and ends the paging early when it returns true, truncated then reports that pages were left unread
+func (d *DynamoDB) queryAll(ctx context.Context, input *dynamodb.QueryInput, stop func() bool) (_ []map[string]types.AttributeValue, pages int, truncated bool, _ error) {
 	items := make([]map[string]types.AttributeValue, 0)
 	var lastEvaluatedKey map[string]types.AttributeValue
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, pages, false, err
+		}
 		input.ExclusiveStartKey = lastEvaluatedKey
-		output, err := d.client.Query(ctx, input)
+		output, err := call(ctx, d, "Query", input.TableName, input, d.reader().Query)
 		if err != nil {
-			return nil, err
+			return nil, pages, false, err
 		}
+		pages++
 		items = append(items, output.Items...)
 		if output.LastEvaluatedKey == nil {
 			break
 		}
+		if stop != nil && stop() {
+			return items, pages, true, nil
+		}
 		lastEvaluatedKey = output.LastEvaluatedKey
 	}
 
-	return items, nil
+	return items, pages, false, nil
+}
+
+// QueryOne is a wrapper around dynamodb.Query that returns the first item matched by the query or error ErrNotFound if there is none.
+// without a FilterExpression the query runs with Limit 1 so only a single item of capacity is read. with one the caller's Limit, or none, is kept since Limit caps the items evaluated
+// before the filter and a limit of 1 would cost a round trip per discarded item, the next pages are then read until a match or the end of the query
+func (d *DynamoDB) QueryOne(ctx context.Context, input *dynamodb.QueryInput) (map[string]types.AttributeValue, error) {
+	in := *input
+	if in.FilterExpression == nil {
+		in.Limit = aws.Int32(1)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		output, err := call(ctx, d, "Query", in.TableName, &in, d.reader().Query)
+		if err != nil {
+			return nil, err
+		}
+		if len(output.Items) > 0 {
+			return output.Items[0], nil
+		}
+		if output.LastEvaluatedKey == nil {
+			return nil, ErrNotFound
+		}
+		in.ExclusiveStartKey = output.LastEvaluatedKey
+	}
 }
 
 // GetQueryCount is a wrapper around dynamodb.Query that returns the count of items that match the provided query. if input.Select is not types.SelectCount it will be set to types.SelectCount
+// the query is paged to completion from input.ExclusiveStartKey, dynamo stops counting at 1MB of evaluated items per call. breaking change: it used to return the count of the first call only
 func (d *DynamoDB) GetQueryCount(ctx context.Context, input dynamodb.QueryInput) (int, error) {
 
 	if input.Select != types.SelectCount {
 		input.Select = types.SelectCount
 	}
 
-	output, err := d.client.Query(ctx, &input)
-	if err != nil {
-		return 0, err
+	var count int
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		output, err := call(ctx, d, "Query", input.TableName, &input, d.reader().Query)
+		if err != nil {
+			return 0, err
+		}
+		count += int(output.Count)
+		if output.LastEvaluatedKey == nil {
+			return count, nil
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
 	}
-	return int(output.Count), nil
 }
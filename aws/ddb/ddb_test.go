@@ -0,0 +1,281 @@
+package ddb_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+	"github.com/jap1998/aws-code-snippets/aws/ddb/ddbtest"
+)
+
+// newFake returns a wrapper around a Fake with a "pk"/"sk" table named "t"
+func newFake(t *testing.T, opts ...ddb.Option) (*ddb.DynamoDB, *ddbtest.Fake) {
+	t.Helper()
+
+	f := ddbtest.NewFake()
+	f.AddTable("t", "pk", "sk")
+
+	return ddb.NewWithClient(f, opts...), f
+}
+
+// key returns the primary key of the "t" table
+func key(pk, sk string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: pk},
+		"sk": &types.AttributeValueMemberS{Value: sk},
+	}
+}
+
+// putItems writes one item per sort key under pk, each with an "n" attribute holding its position
+func putItems(t *testing.T, f *ddbtest.Fake, pk string, sks ...string) {
+	t.Helper()
+
+	for i, sk := range sks {
+		item := key(pk, sk)
+		item["n"] = &types.AttributeValueMemberN{Value: strconv.Itoa(i)}
+		if _, err := f.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("t"), Item: item}); err != nil {
+			t.Fatalf("put %s/%s: %v", pk, sk, err)
+		}
+	}
+}
+
+// deletingUpdater deletes the item right before each UpdateItem reaches the fake, the window a read-then-write existence check loses
+type deletingUpdater struct {
+	*ddbtest.Fake
+}
+
+func (c deletingUpdater) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	if _, err := c.Fake.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: params.TableName, Key: params.Key}); err != nil {
+		return nil, err
+	}
+	return c.Fake.UpdateItem(ctx, params, optFns...)
+}
+
+func TestUpdateIfExistsOrFailDeletedBeforeUpdate(t *testing.T) {
+	_, f := newFake(t)
+	putItems(t, f, "a", "1")
+	d := ddb.NewWithClient(deletingUpdater{f})
+
+	input := &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("t"),
+		Key:                       key("a", "1"),
+		UpdateExpression:          aws.String("SET #v = :v"),
+		ExpressionAttributeNames:  map[string]string{"#v": "v"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":v": &types.AttributeValueMemberS{Value: "x"}},
+	}
+
+	err := d.UpdateIfExistsOrFail(context.Background(), input)
+	if !errors.Is(err, ddb.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	if items := f.Items("t"); len(items) != 0 {
+		t.Fatalf("expected the item to stay deleted, got %v", items)
+	}
+
+	if input.ConditionExpression != nil || len(input.ExpressionAttributeNames) != 1 {
+		t.Fatalf("expected the input to be left untouched, got condition %q names %v", aws.ToString(input.ConditionExpression), input.ExpressionAttributeNames)
+	}
+}
+
+func TestUpdateIfExistsOrFailUpdatesExisting(t *testing.T) {
+	d, f := newFake(t)
+	putItems(t, f, "a", "1")
+
+	err := d.UpdateIfExistsOrFail(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("t"),
+		Key:                       key("a", "1"),
+		UpdateExpression:          aws.String("SET #v = :v"),
+		ExpressionAttributeNames:  map[string]string{"#v": "v"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":v": &types.AttributeValueMemberS{Value: "x"}},
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	items := f.Items("t")
+	if v, ok := items[0]["v"].(*types.AttributeValueMemberS); len(items) != 1 || !ok || v.Value != "x" {
+		t.Fatalf("expected the item to be updated, got %v", items)
+	}
+}
+
+// cancelAfterFirst counts the Query and Scan calls reaching the fake and cancels the context after the first one returns
+type cancelAfterFirst struct {
+	*ddbtest.Fake
+	cancel context.CancelFunc
+	calls  int
+}
+
+func (c *cancelAfterFirst) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	c.calls++
+	defer c.cancel()
+	return c.Fake.Query(ctx, params, optFns...)
+}
+
+func (c *cancelAfterFirst) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	c.calls++
+	defer c.cancel()
+	return c.Fake.Scan(ctx, params, optFns...)
+}
+
+func TestPagingStopsWhenContextIsCanceled(t *testing.T) {
+	for name, run := range map[string]func(*ddb.DynamoDB, context.Context) error{
+		"QueryAll": func(d *ddb.DynamoDB, ctx context.Context) error {
+			_, err := d.QueryAll(ctx, queryInput("a", 2))
+			return err
+		},
+		"ScanAll": func(d *ddb.DynamoDB, ctx context.Context) error {
+			_, err := d.ScanAll(ctx, &dynamodb.ScanInput{TableName: aws.String("t"), Limit: aws.Int32(2)})
+			return err
+		},
+		"QueryWithPagination": func(d *ddb.DynamoDB, ctx context.Context) error {
+			_, err := d.QueryWithPagination(ctx, &ddb.PaginationOps{QueryInput: *queryInput("a", 2), Limit: 10, SkipCount: true})
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, f := newFake(t)
+			var sks []string
+			for i := 0; i < 10; i++ {
+				sks = append(sks, fmt.Sprint(i))
+			}
+			putItems(t, f, "a", sks...)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			client := &cancelAfterFirst{Fake: f, cancel: cancel}
+			err := run(ddb.NewWithClient(client), ctx)
+
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("expected context.Canceled, got %v", err)
+			}
+			if client.calls != 1 {
+				t.Fatalf("expected no call after the first page, got %d calls", client.calls)
+			}
+		})
+	}
+}
+
+func TestGetOneExistsProjectedEmptyItem(t *testing.T) {
+	d, f := newFake(t)
+	putItems(t, f, "a", "1")
+
+	get := func(sk string) *dynamodb.GetItemInput {
+		return &dynamodb.GetItemInput{
+			TableName:                aws.String("t"),
+			Key:                      key("a", sk),
+			ProjectionExpression:     aws.String("#m"),
+			ExpressionAttributeNames: map[string]string{"#m": "missing"},
+		}
+	}
+
+	item, found, err := d.GetOneExists(context.Background(), get("1"))
+	if err != nil {
+		t.Fatalf("get existing: %v", err)
+	}
+	if !found || item == nil || len(item) != 0 {
+		t.Fatalf("expected an existing item with no projected attributes as found and empty, got %v, %v", item, found)
+	}
+
+	item, found, err = d.GetOneExists(context.Background(), get("2"))
+	if err != nil {
+		t.Fatalf("get missing: %v", err)
+	}
+	if found || item != nil {
+		t.Fatalf("expected a missing item as not found, got %v, %v", item, found)
+	}
+
+	// GetOne tells the two apart by ErrNotFound
+	if item, err := d.GetOne(context.Background(), get("1")); err != nil || item == nil {
+		t.Fatalf("expected GetOne to return the empty item, got %v, %v", item, err)
+	}
+	if _, err := d.GetOne(context.Background(), get("2")); !errors.Is(err, ddb.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// queryRecorder records the Limit of every Query call
+type queryRecorder struct {
+	*ddbtest.Fake
+	limits []int32
+}
+
+func (c *queryRecorder) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	c.limits = append(c.limits, aws.ToInt32(params.Limit))
+	return c.Fake.Query(ctx, params, optFns...)
+}
+
+func TestQueryOneFilterKeepsLimit(t *testing.T) {
+	_, f := newFake(t)
+	putItems(t, f, "a", "1", "2", "3", "4", "5")
+	rec := &queryRecorder{Fake: f}
+	d := ddb.NewWithClient(rec)
+
+	input := queryInput("a", 0)
+	input.Limit = nil
+	input.FilterExpression = aws.String("#n = :n")
+	input.ExpressionAttributeNames["#n"] = "n"
+	input.ExpressionAttributeValues[":n"] = &types.AttributeValueMemberN{Value: "3"}
+
+	item, err := d.QueryOne(context.Background(), input)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if sk, _ := item["sk"].(*types.AttributeValueMemberS); sk == nil || sk.Value != "4" {
+		t.Fatalf("expected item 4, got %v", item)
+	}
+	if len(rec.limits) != 1 || rec.limits[0] != 0 {
+		t.Fatalf("expected a single call without a limit, got limits %v", rec.limits)
+	}
+
+	rec.limits = nil
+	if _, err := d.QueryOne(context.Background(), queryInput("a", 10)); err != nil {
+		t.Fatalf("query without filter: %v", err)
+	}
+	if len(rec.limits) != 1 || rec.limits[0] != 1 {
+		t.Fatalf("expected a single call with limit 1, got limits %v", rec.limits)
+	}
+}
+
+func TestQueryOneEventuallyDefaultsPollInterval(t *testing.T) {
+	_, f := newFake(t)
+	rec := &queryRecorder{Fake: f}
+	d := ddb.NewWithClient(rec)
+
+	_, err := d.QueryOneEventually(context.Background(), queryInput("a", 1), 100*time.Millisecond, 0)
+	if !errors.Is(err, ddb.ErrItemNotVisible) {
+		t.Fatalf("expected ErrItemNotVisible, got %v", err)
+	}
+	if len(rec.limits) > 2 {
+		t.Fatalf("expected a zero poll interval not to query back to back, got %d queries", len(rec.limits))
+	}
+}
+
+func TestQueryAllWithinStopsAfterBudget(t *testing.T) {
+	d, f := newFake(t)
+	putItems(t, f, "a", "1", "2", "3", "4", "5")
+
+	items, truncated, err := d.QueryAllWithin(context.Background(), queryInput("a", 2), 0)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(items) != 2 || !truncated {
+		t.Fatalf("expected the first page only and truncated, got %d items, truncated %v", len(items), truncated)
+	}
+
+	items, truncated, err = d.QueryAllWithin(context.Background(), queryInput("a", 2), time.Minute)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if len(items) != 5 || truncated {
+		t.Fatalf("expected every item and not truncated, got %d items, truncated %v", len(items), truncated)
+	}
+}
@@ -0,0 +1,827 @@
+package ddbtest
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// exprContext resolves the #name and :value placeholders of an expression
+type exprContext struct {
+	names  map[string]string
+	values map[string]types.AttributeValue
+}
+
+// lexer splits an expression into tokens: words, #names, :values, operators and punctuation
+type lexer struct {
+	tokens []string
+	pos    int
+}
+
+func newLexer(expr string) (*lexer, error) {
+	var tokens []string
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune("(),.[]+-=", c):
+			tokens = append(tokens, string(c))
+			i++
+		case c == '<' || c == '>':
+			if i+1 < len(expr) && (expr[i+1] == '=' || (c == '<' && expr[i+1] == '>')) {
+				tokens = append(tokens, expr[i:i+2])
+				i += 2
+			} else {
+				tokens = append(tokens, string(c))
+				i++
+			}
+		case c == '#' || c == ':' || c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c):
+			j := i + 1
+			for j < len(expr) && (expr[j] == '_' || unicode.IsLetter(rune(expr[j])) || unicode.IsDigit(rune(expr[j]))) {
+				j++
+			}
+			tokens = append(tokens, expr[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected character %q in expression %q", c, expr)
+		}
+	}
+	return &lexer{tokens: tokens}, nil
+}
+
+func (l *lexer) peek() string {
+	if l.pos < len(l.tokens) {
+		return l.tokens[l.pos]
+	}
+	return ""
+}
+
+func (l *lexer) next() string {
+	t := l.peek()
+	l.pos++
+	return t
+}
+
+// keyword reports whether the next token is the provided keyword, consuming it when it is
+func (l *lexer) keyword(kw string) bool {
+	if strings.EqualFold(l.peek(), kw) {
+		l.pos++
+		return true
+	}
+	return false
+}
+
+func (l *lexer) expect(t string) error {
+	if got := l.next(); got != t {
+		return fmt.Errorf("expected %q, got %q", t, got)
+	}
+	return nil
+}
+
+// path is an attribute path, a list of map keys from the top level of the item
+type path []string
+
+func (l *lexer) path(ctx *exprContext) (path, error) {
+	var p path
+	for {
+		name, err := ctx.name(l.next())
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, name)
+		if l.peek() == "[" {
+			return nil, fmt.Errorf("list index paths are not supported")
+		}
+		if l.peek() != "." {
+			return p, nil
+		}
+		l.next()
+	}
+}
+
+func (ctx *exprContext) name(tok string) (string, error) {
+	if strings.HasPrefix(tok, "#") {
+		name, ok := ctx.names[tok]
+		if !ok {
+			return "", fmt.Errorf("expression attribute name %s is not defined", tok)
+		}
+		return name, nil
+	}
+	if tok == "" || strings.HasPrefix(tok, ":") || !isWord(tok) {
+		return "", fmt.Errorf("expected an attribute name, got %q", tok)
+	}
+	return tok, nil
+}
+
+func (ctx *exprContext) value(tok string) (types.AttributeValue, error) {
+	v, ok := ctx.values[tok]
+	if !ok {
+		return nil, fmt.Errorf("expression attribute value %s is not defined", tok)
+	}
+	return v, nil
+}
+
+func isWord(tok string) bool {
+	for _, c := range tok {
+		if c != '_' && !unicode.IsLetter(c) && !unicode.IsDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve returns the value at p in item, nil when any step is missing
+func resolve(item map[string]types.AttributeValue, p path) types.AttributeValue {
+	var cur types.AttributeValue = &types.AttributeValueMemberM{Value: item}
+	for _, name := range p {
+		m, ok := cur.(*types.AttributeValueMemberM)
+		if !ok {
+			return nil
+		}
+		if cur, ok = m.Value[name]; !ok {
+			return nil
+		}
+	}
+	return cur
+}
+
+// condition is a parsed condition, key condition or filter expression
+type condition func(item map[string]types.AttributeValue) bool
+
+// operand is a value inside a condition, either a path into the item or a :value
+type operand func(item map[string]types.AttributeValue) types.AttributeValue
+
+// parseCondition parses a condition expression, an empty expression matches every item
+func parseCondition(expr string, ctx *exprContext) (condition, error) {
+	if strings.TrimSpace(expr) == "" {
+		return func(map[string]types.AttributeValue) bool { return true }, nil
+	}
+
+	l, err := newLexer(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := l.or(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", expr, err)
+	}
+	if l.peek() != "" {
+		return nil, fmt.Errorf("invalid expression %q: unexpected %q", expr, l.peek())
+	}
+
+	return c, nil
+}
+
+func (l *lexer) or(ctx *exprContext) (condition, error) {
+	left, err := l.and(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for l.keyword("OR") {
+		right, err := l.and(ctx)
+		if err != nil {
+			return nil, err
+		}
+		a := left
+		left = func(item map[string]types.AttributeValue) bool { return a(item) || right(item) }
+	}
+	return left, nil
+}
+
+func (l *lexer) and(ctx *exprContext) (condition, error) {
+	left, err := l.not(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for l.keyword("AND") {
+		right, err := l.not(ctx)
+		if err != nil {
+			return nil, err
+		}
+		a := left
+		left = func(item map[string]types.AttributeValue) bool { return a(item) && right(item) }
+	}
+	return left, nil
+}
+
+func (l *lexer) not(ctx *exprContext) (condition, error) {
+	if l.keyword("NOT") {
+		c, err := l.not(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) bool { return !c(item) }, nil
+	}
+	return l.primary(ctx)
+}
+
+func (l *lexer) primary(ctx *exprContext) (condition, error) {
+	if l.peek() == "(" {
+		l.next()
+		c, err := l.or(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return c, l.expect(")")
+	}
+
+	switch fn := strings.ToLower(l.peek()); fn {
+	case "attribute_exists", "attribute_not_exists":
+		l.next()
+		if err := l.expect("("); err != nil {
+			return nil, err
+		}
+		p, err := l.path(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.expect(")"); err != nil {
+			return nil, err
+		}
+		exists := fn == "attribute_exists"
+		return func(item map[string]types.AttributeValue) bool { return (resolve(item, p) != nil) == exists }, nil
+	case "begins_with", "contains":
+		l.next()
+		if err := l.expect("("); err != nil {
+			return nil, err
+		}
+		a, err := l.operand(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.expect(","); err != nil {
+			return nil, err
+		}
+		b, err := l.operand(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.expect(")"); err != nil {
+			return nil, err
+		}
+		if fn == "begins_with" {
+			return func(item map[string]types.AttributeValue) bool { return beginsWith(a(item), b(item)) }, nil
+		}
+		return func(item map[string]types.AttributeValue) bool { return contains(a(item), b(item)) }, nil
+	}
+
+	left, err := l.operand(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.keyword("BETWEEN") {
+		low, err := l.operand(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !l.keyword("AND") {
+			return nil, fmt.Errorf("expected AND in BETWEEN")
+		}
+		high, err := l.operand(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) bool {
+			v := left(item)
+			c1, ok1 := compare(v, low(item))
+			c2, ok2 := compare(v, high(item))
+			return ok1 && ok2 && c1 >= 0 && c2 <= 0
+		}, nil
+	}
+
+	if l.keyword("IN") {
+		if err := l.expect("("); err != nil {
+			return nil, err
+		}
+		var list []operand
+		for {
+			o, err := l.operand(ctx)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, o)
+			if l.peek() != "," {
+				break
+			}
+			l.next()
+		}
+		if err := l.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) bool {
+			v := left(item)
+			for _, o := range list {
+				if equal(v, o(item)) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	op := l.next()
+	right, err := l.operand(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "=":
+		return func(item map[string]types.AttributeValue) bool { return equal(left(item), right(item)) }, nil
+	case "<>":
+		return func(item map[string]types.AttributeValue) bool {
+			a, b := left(item), right(item)
+			return a != nil && b != nil && !equal(a, b)
+		}, nil
+	case "<", "<=", ">", ">=":
+		return func(item map[string]types.AttributeValue) bool {
+			c, ok := compare(left(item), right(item))
+			if !ok {
+				return false
+			}
+			switch op {
+			case "<":
+				return c < 0
+			case "<=":
+				return c <= 0
+			case ">":
+				return c > 0
+			default:
+				return c >= 0
+			}
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", op)
+	}
+}
+
+func (l *lexer) operand(ctx *exprContext) (operand, error) {
+	tok := l.peek()
+	if strings.HasPrefix(tok, ":") {
+		l.next()
+		v, err := ctx.value(tok)
+		if err != nil {
+			return nil, err
+		}
+		return func(map[string]types.AttributeValue) types.AttributeValue { return v }, nil
+	}
+
+	if strings.EqualFold(tok, "size") {
+		l.next()
+		if err := l.expect("("); err != nil {
+			return nil, err
+		}
+		p, err := l.path(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) types.AttributeValue { return size(resolve(item, p)) }, nil
+	}
+
+	p, err := l.path(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(item map[string]types.AttributeValue) types.AttributeValue { return resolve(item, p) }, nil
+}
+
+// compare orders two scalar values of the same type, ok is false when they can't be compared
+func compare(a, b types.AttributeValue) (int, bool) {
+	switch x := a.(type) {
+	case *types.AttributeValueMemberS:
+		if y, ok := b.(*types.AttributeValueMemberS); ok {
+			return strings.Compare(x.Value, y.Value), true
+		}
+	case *types.AttributeValueMemberN:
+		if y, ok := b.(*types.AttributeValueMemberN); ok {
+			xr, ok1 := new(big.Rat).SetString(x.Value)
+			yr, ok2 := new(big.Rat).SetString(y.Value)
+			if ok1 && ok2 {
+				return xr.Cmp(yr), true
+			}
+		}
+	case *types.AttributeValueMemberB:
+		if y, ok := b.(*types.AttributeValueMemberB); ok {
+			return bytes.Compare(x.Value, y.Value), true
+		}
+	}
+	return 0, false
+}
+
+func equal(a, b types.AttributeValue) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	if c, ok := compare(a, b); ok {
+		return c == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func beginsWith(a, b types.AttributeValue) bool {
+	switch x := a.(type) {
+	case *types.AttributeValueMemberS:
+		if y, ok := b.(*types.AttributeValueMemberS); ok {
+			return strings.HasPrefix(x.Value, y.Value)
+		}
+	case *types.AttributeValueMemberB:
+		if y, ok := b.(*types.AttributeValueMemberB); ok {
+			return bytes.HasPrefix(x.Value, y.Value)
+		}
+	}
+	return false
+}
+
+func contains(a, b types.AttributeValue) bool {
+	switch x := a.(type) {
+	case *types.AttributeValueMemberS:
+		if y, ok := b.(*types.AttributeValueMemberS); ok {
+			return strings.Contains(x.Value, y.Value)
+		}
+	case *types.AttributeValueMemberSS:
+		if y, ok := b.(*types.AttributeValueMemberS); ok {
+			for _, s := range x.Value {
+				if s == y.Value {
+					return true
+				}
+			}
+		}
+	case *types.AttributeValueMemberNS:
+		for _, n := range x.Value {
+			if equal(&types.AttributeValueMemberN{Value: n}, b) {
+				return true
+			}
+		}
+	case *types.AttributeValueMemberL:
+		for _, e := range x.Value {
+			if equal(e, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func size(v types.AttributeValue) types.AttributeValue {
+	var n int
+	switch x := v.(type) {
+	case *types.AttributeValueMemberS:
+		n = len(x.Value)
+	case *types.AttributeValueMemberB:
+		n = len(x.Value)
+	case *types.AttributeValueMemberSS:
+		n = len(x.Value)
+	case *types.AttributeValueMemberNS:
+		n = len(x.Value)
+	case *types.AttributeValueMemberBS:
+		n = len(x.Value)
+	case *types.AttributeValueMemberL:
+		n = len(x.Value)
+	case *types.AttributeValueMemberM:
+		n = len(x.Value)
+	default:
+		return nil
+	}
+	return &types.AttributeValueMemberN{Value: fmt.Sprint(n)}
+}
+
+// update is a parsed update expression, it mutates item in place and returns the names of the top level attributes it touched
+type update func(item map[string]types.AttributeValue) ([]string, error)
+
+// parseUpdate parses an update expression made of SET, REMOVE, ADD and DELETE clauses on top level attributes
+func parseUpdate(expr string, ctx *exprContext) (update, error) {
+	l, err := newLexer(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []update
+	for l.peek() != "" {
+		clause := strings.ToUpper(l.next())
+		for {
+			name, err := l.topLevel(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("invalid update expression %q: %w", expr, err)
+			}
+
+			var action update
+			switch clause {
+			case "SET":
+				if err := l.expect("="); err != nil {
+					return nil, fmt.Errorf("invalid update expression %q: %w", expr, err)
+				}
+				v, err := l.setValue(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("invalid update expression %q: %w", expr, err)
+				}
+				action = func(item map[string]types.AttributeValue) ([]string, error) {
+					nv, err := v(item)
+					if err != nil {
+						return nil, err
+					}
+					item[name] = nv
+					return []string{name}, nil
+				}
+			case "REMOVE":
+				action = func(item map[string]types.AttributeValue) ([]string, error) {
+					delete(item, name)
+					return nil, nil
+				}
+			case "ADD", "DELETE":
+				tok := l.next()
+				v, err := ctx.value(tok)
+				if err != nil {
+					return nil, fmt.Errorf("invalid update expression %q: %w", expr, err)
+				}
+				add := clause == "ADD"
+				action = func(item map[string]types.AttributeValue) ([]string, error) {
+					nv, err := addOrDelete(item[name], v, add)
+					if err != nil {
+						return nil, err
+					}
+					if nv == nil {
+						delete(item, name)
+						return nil, nil
+					}
+					item[name] = nv
+					return []string{name}, nil
+				}
+			default:
+				return nil, fmt.Errorf("invalid update expression %q: unknown clause %q", expr, clause)
+			}
+			actions = append(actions, action)
+
+			if l.peek() != "," {
+				break
+			}
+			l.next()
+		}
+	}
+
+	return func(item map[string]types.AttributeValue) ([]string, error) {
+		var touched []string
+		for _, action := range actions {
+			names, err := action(item)
+			if err != nil {
+				return nil, err
+			}
+			touched = append(touched, names...)
+		}
+		return touched, nil
+	}, nil
+}
+
+// topLevel parses a path that must be a single top level attribute name
+func (l *lexer) topLevel(ctx *exprContext) (string, error) {
+	p, err := l.path(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(p) != 1 {
+		return "", fmt.Errorf("nested paths are not supported in updates")
+	}
+	return p[0], nil
+}
+
+type setValue func(item map[string]types.AttributeValue) (types.AttributeValue, error)
+
+// setValue parses the right hand side of a SET action: an operand, optionally followed by + or - and another operand
+func (l *lexer) setValue(ctx *exprContext) (setValue, error) {
+	left, err := l.setOperand(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if op := l.peek(); op == "+" || op == "-" {
+		l.next()
+		right, err := l.setOperand(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+			a, err := left(item)
+			if err != nil {
+				return nil, err
+			}
+			b, err := right(item)
+			if err != nil {
+				return nil, err
+			}
+			return arithmetic(a, b, op == "-")
+		}, nil
+	}
+
+	return left, nil
+}
+
+func (l *lexer) setOperand(ctx *exprContext) (setValue, error) {
+	switch strings.ToLower(l.peek()) {
+	case "if_not_exists":
+		l.next()
+		if err := l.expect("("); err != nil {
+			return nil, err
+		}
+		p, err := l.path(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.expect(","); err != nil {
+			return nil, err
+		}
+		fallback, err := l.setOperand(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+			if v := resolve(item, p); v != nil {
+				return v, nil
+			}
+			return fallback(item)
+		}, nil
+	case "list_append":
+		l.next()
+		if err := l.expect("("); err != nil {
+			return nil, err
+		}
+		a, err := l.setOperand(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.expect(","); err != nil {
+			return nil, err
+		}
+		b, err := l.setOperand(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := l.expect(")"); err != nil {
+			return nil, err
+		}
+		return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+			x, err := a(item)
+			if err != nil {
+				return nil, err
+			}
+			y, err := b(item)
+			if err != nil {
+				return nil, err
+			}
+			xl, ok1 := x.(*types.AttributeValueMemberL)
+			yl, ok2 := y.(*types.AttributeValueMemberL)
+			if !ok1 || !ok2 {
+				return nil, fmt.Errorf("list_append operands must be lists")
+			}
+			return &types.AttributeValueMemberL{Value: append(append([]types.AttributeValue{}, xl.Value...), yl.Value...)}, nil
+		}, nil
+	}
+
+	o, err := l.operand(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+		v := o(item)
+		if v == nil {
+			return nil, fmt.Errorf("the provided expression refers to an attribute that does not exist in the item")
+		}
+		return v, nil
+	}, nil
+}
+
+// arithmetic adds or subtracts two numbers keeping arbitrary precision
+func arithmetic(a, b types.AttributeValue, subtract bool) (types.AttributeValue, error) {
+	x, ok1 := a.(*types.AttributeValueMemberN)
+	y, ok2 := b.(*types.AttributeValueMemberN)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("an operand in the update expression has an incorrect data type")
+	}
+
+	xr, ok1 := new(big.Rat).SetString(x.Value)
+	yr, ok2 := new(big.Rat).SetString(y.Value)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("invalid number")
+	}
+
+	if subtract {
+		xr.Sub(xr, yr)
+	} else {
+		xr.Add(xr, yr)
+	}
+
+	return &types.AttributeValueMemberN{Value: formatRat(xr)}, nil
+}
+
+func formatRat(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	s := strings.TrimRight(r.FloatString(38), "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// addOrDelete applies an ADD or DELETE action, ADD sums numbers and unions sets while DELETE removes elements from a set, a nil result removes the attribute
+func addOrDelete(cur, v types.AttributeValue, add bool) (types.AttributeValue, error) {
+	if add {
+		if n, ok := v.(*types.AttributeValueMemberN); ok {
+			if cur == nil {
+				return n, nil
+			}
+			return arithmetic(cur, n, false)
+		}
+	}
+
+	switch x := v.(type) {
+	case *types.AttributeValueMemberSS:
+		var existing []string
+		if c, ok := cur.(*types.AttributeValueMemberSS); ok {
+			existing = c.Value
+		} else if cur != nil {
+			return nil, fmt.Errorf("an operand in the update expression has an incorrect data type")
+		}
+		out := setOp(existing, x.Value, add)
+		if len(out) == 0 {
+			return nil, nil
+		}
+		return &types.AttributeValueMemberSS{Value: out}, nil
+	case *types.AttributeValueMemberNS:
+		var existing []string
+		if c, ok := cur.(*types.AttributeValueMemberNS); ok {
+			existing = c.Value
+		} else if cur != nil {
+			return nil, fmt.Errorf("an operand in the update expression has an incorrect data type")
+		}
+		out := setOp(existing, x.Value, add)
+		if len(out) == 0 {
+			return nil, nil
+		}
+		return &types.AttributeValueMemberNS{Value: out}, nil
+	}
+
+	return nil, fmt.Errorf("ADD and DELETE only support numbers and string or number sets")
+}
+
+func setOp(existing, values []string, add bool) []string {
+	out := make([]string, 0, len(existing)+len(values))
+	drop := make(map[string]bool)
+	if !add {
+		for _, v := range values {
+			drop[v] = true
+		}
+	}
+	seen := make(map[string]bool)
+	for _, v := range existing {
+		if !drop[v] && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	if add {
+		for _, v := range values {
+			if !seen[v] {
+				seen[v] = true
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+// parseProjection parses a projection expression into the top level attribute names it keeps
+func parseProjection(expr string, ctx *exprContext) ([]string, error) {
+	l, err := newLexer(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for {
+		p, err := l.path(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("invalid projection expression %q: %w", expr, err)
+		}
+		names = append(names, p[0])
+		if l.peek() != "," {
+			break
+		}
+		l.next()
+	}
+	if l.peek() != "" {
+		return nil, fmt.Errorf("invalid projection expression %q: unexpected %q", expr, l.peek())
+	}
+
+	return names, nil
+}
@@ -0,0 +1,243 @@
+package ddbtest
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+func s(v string) types.AttributeValue { return &types.AttributeValueMemberS{Value: v} }
+func n(v string) types.AttributeValue { return &types.AttributeValueMemberN{Value: v} }
+
+// testItem is the item every condition below is evaluated against
+func testItem() map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"pk":   s("user#1"),
+		"age":  n("42"),
+		"tags": &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"list": &types.AttributeValueMemberL{Value: []types.AttributeValue{s("x"), n("1")}},
+		"meta": &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"city": s("Lima")}},
+		"bin":  &types.AttributeValueMemberB{Value: []byte{0x01, 0x02}},
+	}
+}
+
+func testContext() *exprContext {
+	return &exprContext{
+		names: map[string]string{"#pk": "pk", "#age": "age", "#m": "meta", "#c": "city", "#t": "tags"},
+		values: map[string]types.AttributeValue{
+			":pk":    s("user#1"),
+			":pre":   s("user"),
+			":a":     s("a"),
+			":x":     s("x"),
+			":n1":    n("1"),
+			":n40":   n("40"),
+			":n42":   n("42.0"),
+			":n50":   n("50"),
+			":lima":  s("Lima"),
+			":other": s("other"),
+			":b":     &types.AttributeValueMemberB{Value: []byte{0x01}},
+		},
+	}
+}
+
+func TestLexerTokens(t *testing.T) {
+	for expr, want := range map[string][]string{
+		"#a <> :b AND size(x.y) >= :c": {"#a", "<>", ":b", "AND", "size", "(", "x", ".", "y", ")", ">=", ":c"},
+		"a<b OR c>d":                   {"a", "<", "b", "OR", "c", ">", "d"},
+		"SET n = n + :one, m = :m":     {"SET", "n", "=", "n", "+", ":one", ",", "m", "=", ":m"},
+		"  ":                           nil,
+	} {
+		l, err := newLexer(expr)
+		if err != nil {
+			t.Fatalf("%q: %v", expr, err)
+		}
+		if !slices.Equal(l.tokens, want) {
+			t.Errorf("%q: expected %q, got %q", expr, want, l.tokens)
+		}
+	}
+
+	if _, err := newLexer("a = :b; DROP"); err == nil {
+		t.Fatal("expected an error for an unexpected character")
+	}
+}
+
+func TestParseConditionEvaluates(t *testing.T) {
+	for expr, want := range map[string]bool{
+		"":                                      true,
+		"#pk = :pk":                             true,
+		"#pk <> :pk":                            false,
+		"pk <> :other":                          true,
+		"missing <> :other":                     false,
+		"#age = :n42":                           true,
+		"#age > :n40 AND #age < :n50":           true,
+		"#age >= :n50 OR #age <= :n40":          false,
+		"#age BETWEEN :n40 AND :n50":            true,
+		"#age BETWEEN :n50 AND :n40":            false,
+		"#age IN (:n1, :n42)":                   true,
+		"#age IN (:n1)":                         false,
+		"NOT #age = :n1":                        true,
+		"NOT (#age = :n42 OR #pk = :x)":         false,
+		"attribute_exists(#m.#c)":               true,
+		"attribute_not_exists(#m.zip)":          true,
+		"attribute_exists(nope)":                false,
+		"#m.#c = :lima":                         true,
+		"begins_with(#pk, :pre)":                true,
+		"begins_with(bin, :b)":                  true,
+		"begins_with(#age, :pre)":               false,
+		"contains(#t, :a)":                      true,
+		"contains(#pk, :x)":                     false,
+		"contains(list, :x)":                    true,
+		"contains(list, :n1)":                   true,
+		"size(#t) = :n1":                        false,
+		"size(#m) = :n1 AND size(#pk) > :n1":    true,
+		"#pk = :pk and not attribute_exists(x)": true,
+		"#pk < :n50":                            false,
+	} {
+		c, err := parseCondition(expr, testContext())
+		if err != nil {
+			t.Errorf("%q: %v", expr, err)
+			continue
+		}
+		if got := c(testItem()); got != want {
+			t.Errorf("%q: expected %v, got %v", expr, want, got)
+		}
+	}
+}
+
+func TestParseConditionErrors(t *testing.T) {
+	for expr, want := range map[string]string{
+		"#missing = :pk":        "#missing is not defined",
+		"#pk = :missing":        ":missing is not defined",
+		"#pk = :pk :pk":         "unexpected",
+		"(#pk = :pk":            `expected ")"`,
+		"#age BETWEEN :n1 :n50": "expected AND",
+		"#pk ! :pk":             "unexpected character",
+		"list[0] = :x":          "list index paths are not supported",
+		"begins_with(#pk :pre)": `expected ","`,
+		"#pk LIKE :pk":          "unsupported operator",
+		"attribute_exists(:pk)": "expected an attribute name",
+	} {
+		_, err := parseCondition(expr, testContext())
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: expected an error containing %q, got %v", expr, want, err)
+		}
+	}
+}
+
+func TestParseUpdateApplies(t *testing.T) {
+	ctx := &exprContext{
+		names: map[string]string{"#c": "count"},
+		values: map[string]types.AttributeValue{
+			":one":  n("1"),
+			":half": n("0.5"),
+			":zero": n("0"),
+			":l":    &types.AttributeValueMemberL{Value: []types.AttributeValue{s("z")}},
+			":ss":   &types.AttributeValueMemberSS{Value: []string{"b", "c"}},
+			":sa":   &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+			":name": s("bob"),
+		},
+	}
+
+	item := map[string]types.AttributeValue{
+		"count": n("10"),
+		"list":  &types.AttributeValueMemberL{Value: []types.AttributeValue{s("y")}},
+		"tags":  &types.AttributeValueMemberSS{Value: []string{"a"}},
+		"gone":  s("x"),
+	}
+
+	upd, err := parseUpdate("SET #c = #c - :half, name = if_not_exists(name, :name), list = list_append(list, :l), fresh = if_not_exists(fresh, :zero) "+
+		"REMOVE gone ADD tags :ss, total :one DELETE tags :sa", ctx)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	touched, err := upd(item)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	want := map[string]types.AttributeValue{
+		"count": n("9.5"),
+		"name":  s("bob"),
+		"list":  &types.AttributeValueMemberL{Value: []types.AttributeValue{s("y"), s("z")}},
+		"fresh": n("0"),
+		"tags":  &types.AttributeValueMemberSS{Value: []string{"c"}},
+		"total": n("1"),
+	}
+	if !reflect.DeepEqual(item, want) {
+		t.Fatalf("expected %v, got %v", want, item)
+	}
+	if wantTouched := []string{"count", "name", "list", "fresh", "tags", "total", "tags"}; !slices.Equal(touched, wantTouched) {
+		t.Fatalf("expected touched %v, got %v", wantTouched, touched)
+	}
+}
+
+func TestParseUpdateErrors(t *testing.T) {
+	ctx := &exprContext{values: map[string]types.AttributeValue{":s": s("x"), ":one": n("1")}}
+	for expr, want := range map[string]string{
+		"SET a.b = :s":                   "nested paths are not supported",
+		"SET a :s":                       `expected "="`,
+		"UPSERT a = :s":                  "unknown clause",
+		"ADD a :missing":                 ":missing is not defined",
+		"SET a = missing":                "",
+		"SET a = str + :one":             "",
+		"ADD str :one":                   "",
+		"ADD num :s":                     "",
+		"SET a = list_append(num, :one)": "",
+	} {
+		upd, err := parseUpdate(expr, ctx)
+		if want != "" {
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("%q: expected a parse error containing %q, got %v", expr, want, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: expected it to parse, got %v", expr, err)
+			continue
+		}
+		if _, err := upd(map[string]types.AttributeValue{"str": s("x"), "num": n("1")}); err == nil {
+			t.Errorf("%q: expected an error applying it", expr)
+		}
+	}
+}
+
+func TestParseProjection(t *testing.T) {
+	ctx := &exprContext{names: map[string]string{"#d": "data"}}
+
+	names, err := parseProjection("pk, #d, meta.city", ctx)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if want := []string{"pk", "data", "meta"}; !slices.Equal(names, want) {
+		t.Fatalf("expected %v, got %v", want, names)
+	}
+
+	if _, err := parseProjection("pk sk", ctx); err == nil {
+		t.Fatal("expected an error for a missing comma")
+	}
+}
+
+func TestCompareAndEqual(t *testing.T) {
+	if c, ok := compare(n("1e2"), n("99.5")); !ok || c <= 0 {
+		t.Fatalf("expected 1e2 > 99.5, got %d, %v", c, ok)
+	}
+	if _, ok := compare(s("1"), n("1")); ok {
+		t.Fatal("expected a string and a number not to compare")
+	}
+	if !equal(n("1.50"), n("1.5")) {
+		t.Fatal("expected numbers to be equal by value")
+	}
+	if equal(nil, nil) {
+		t.Fatal("expected missing values never to be equal")
+	}
+	if !equal(&types.AttributeValueMemberL{Value: []types.AttributeValue{s("a")}}, &types.AttributeValueMemberL{Value: []types.AttributeValue{s("a")}}) {
+		t.Fatal("expected equal lists to be equal")
+	}
+	if sum, err := arithmetic(n("0.1"), n("0.2"), false); err != nil || !reflect.DeepEqual(sum, n("0.3")) {
+		t.Fatalf("expected 0.1 + 0.2 to be exactly 0.3, got %v, %v", sum, err)
+	}
+}
@@ -0,0 +1,742 @@
+// Package ddbtest provides an in-memory fake of the DynamoDB API for unit testing code built on the ddb wrapper without DynamoDB Local.
+//
+// Fake is deliberately small, it is not an emulator and only covers what application tests usually need:
+//   - GetItem, PutItem, DeleteItem, UpdateItem, Query, Scan, BatchGetItem, BatchWriteItem, CreateTable and DescribeTable are supported, every other DynamoAPI method returns an error
+//   - condition, key condition and filter expressions support comparisons, BETWEEN, IN, AND/OR/NOT, attribute_exists, attribute_not_exists, begins_with, contains and size
+//   - update expressions support SET (values, + and -, if_not_exists, list_append), REMOVE, ADD and DELETE on top level attributes only, list index paths are not supported anywhere
+//   - projection expressions keep top level attributes only
+//   - there are no capacity limits, throttling, 1MB page limits, transactions, streams or TTL, and no validation beyond what is needed to evaluate requests
+//   - parallel scans spread items across segments by a hash of their partition key
+//   - pages are bounded by Limit or by PageSize when set, which is the way to exercise pagination in tests
+package ddbtest
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+)
+
+var _ ddb.DynamoAPI = (*Fake)(nil)
+
+// Fake is an in-memory implementation of ddb.DynamoAPI, see the package documentation for what it does not support. it is safe for concurrent use
+type Fake struct {
+	// PageSize caps the number of items evaluated per Query or Scan page when the request has no Limit, 0 means unlimited
+	PageSize int
+
+	mu     sync.Mutex
+	tables map[string]*table
+}
+
+// table is a fake table, items are keyed by the string form of their primary key
+type table struct {
+	desc    types.TableDescription
+	hashKey string
+	sortKey string
+	indexes map[string][2]string
+	items   map[string]map[string]types.AttributeValue
+}
+
+// NewFake returns an empty Fake, create tables with AddTable or CreateTable before using them
+func NewFake() *Fake {
+	return &Fake{tables: make(map[string]*table)}
+}
+
+// AddTable creates a table with a string hash key and an optional string sort key, pass an empty sortKey for a hash only table
+func (f *Fake) AddTable(name, hashKey, sortKey string) {
+	input := &dynamodb.CreateTableInput{
+		TableName:            aws.String(name),
+		KeySchema:            []types.KeySchemaElement{{AttributeName: aws.String(hashKey), KeyType: types.KeyTypeHash}},
+		AttributeDefinitions: []types.AttributeDefinition{{AttributeName: aws.String(hashKey), AttributeType: types.ScalarAttributeTypeS}},
+		BillingMode:          types.BillingModePayPerRequest,
+	}
+	if sortKey != "" {
+		input.KeySchema = append(input.KeySchema, types.KeySchemaElement{AttributeName: aws.String(sortKey), KeyType: types.KeyTypeRange})
+		input.AttributeDefinitions = append(input.AttributeDefinitions, types.AttributeDefinition{AttributeName: aws.String(sortKey), AttributeType: types.ScalarAttributeTypeS})
+	}
+
+	_, _ = f.CreateTable(context.Background(), input)
+}
+
+// Items returns a copy of every item stored in the table, in primary key order
+func (f *Fake) Items(tableName string) []map[string]types.AttributeValue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, ok := f.tables[tableName]
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(t.items))
+	for k := range t.items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	items := make([]map[string]types.AttributeValue, len(keys))
+	for i, k := range keys {
+		items[i] = maps.Clone(t.items[k])
+	}
+	return items
+}
+
+// CreateTable registers a new table with the key schema and indexes of the input, it is ACTIVE right away
+func (f *Fake) CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.tables == nil {
+		f.tables = make(map[string]*table)
+	}
+
+	name := aws.ToString(params.TableName)
+	if _, ok := f.tables[name]; ok {
+		return nil, &types.ResourceInUseException{Message: aws.String("Table already exists: " + name)}
+	}
+
+	t := &table{
+		indexes: make(map[string][2]string),
+		items:   make(map[string]map[string]types.AttributeValue),
+	}
+	t.hashKey, t.sortKey = keyNames(params.KeySchema)
+
+	t.desc = types.TableDescription{
+		TableName:            params.TableName,
+		TableArn:             aws.String("arn:aws:dynamodb:local:000000000000:table/" + name),
+		TableStatus:          types.TableStatusActive,
+		KeySchema:            params.KeySchema,
+		AttributeDefinitions: params.AttributeDefinitions,
+		BillingModeSummary:   &types.BillingModeSummary{BillingMode: params.BillingMode},
+	}
+	if params.BillingMode == "" {
+		t.desc.BillingModeSummary.BillingMode = types.BillingModeProvisioned
+	}
+
+	for _, gsi := range params.GlobalSecondaryIndexes {
+		hash, rangeKey := keyNames(gsi.KeySchema)
+		t.indexes[aws.ToString(gsi.IndexName)] = [2]string{hash, rangeKey}
+		t.desc.GlobalSecondaryIndexes = append(t.desc.GlobalSecondaryIndexes, types.GlobalSecondaryIndexDescription{
+			IndexName:   gsi.IndexName,
+			KeySchema:   gsi.KeySchema,
+			Projection:  gsi.Projection,
+			IndexStatus: types.IndexStatusActive,
+			Backfilling: aws.Bool(false),
+		})
+	}
+	for _, lsi := range params.LocalSecondaryIndexes {
+		hash, rangeKey := keyNames(lsi.KeySchema)
+		t.indexes[aws.ToString(lsi.IndexName)] = [2]string{hash, rangeKey}
+		t.desc.LocalSecondaryIndexes = append(t.desc.LocalSecondaryIndexes, types.LocalSecondaryIndexDescription{
+			IndexName:  lsi.IndexName,
+			KeySchema:  lsi.KeySchema,
+			Projection: lsi.Projection,
+		})
+	}
+
+	f.tables[name] = t
+
+	desc := t.desc
+	return &dynamodb.CreateTableOutput{TableDescription: &desc}, nil
+}
+
+// DescribeTable returns the description the table was created with along with its current item count
+func (f *Fake) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	desc := t.desc
+	desc.ItemCount = aws.Int64(int64(len(t.items)))
+
+	return &dynamodb.DescribeTableOutput{Table: &desc}, nil
+}
+
+// GetItem returns the item with the provided key, applying the projection expression if any
+func (f *Fake) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := t.keyID(params.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	item, ok := t.items[id]
+	if !ok {
+		return &dynamodb.GetItemOutput{}, nil
+	}
+
+	ectx := &exprContext{names: params.ExpressionAttributeNames}
+	item, err = project(item, aws.ToString(params.ProjectionExpression), ectx)
+	if err != nil {
+		return nil, validation(err)
+	}
+
+	return &dynamodb.GetItemOutput{Item: item}, nil
+}
+
+// PutItem stores the item, replacing any item with the same key, once the condition expression holds
+func (f *Fake) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := t.keyID(params.Item)
+	if err != nil {
+		return nil, err
+	}
+
+	old := t.items[id]
+	ectx := &exprContext{names: params.ExpressionAttributeNames, values: params.ExpressionAttributeValues}
+	if err := checkCondition(aws.ToString(params.ConditionExpression), ectx, old, params.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+
+	t.items[id] = maps.Clone(params.Item)
+
+	output := &dynamodb.PutItemOutput{}
+	if params.ReturnValues == types.ReturnValueAllOld {
+		output.Attributes = old
+	}
+	return output, nil
+}
+
+// DeleteItem removes the item with the provided key once the condition expression holds, deleting a missing item is not an error
+func (f *Fake) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := t.keyID(params.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	old := t.items[id]
+	ectx := &exprContext{names: params.ExpressionAttributeNames, values: params.ExpressionAttributeValues}
+	if err := checkCondition(aws.ToString(params.ConditionExpression), ectx, old, params.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+
+	delete(t.items, id)
+
+	output := &dynamodb.DeleteItemOutput{}
+	if params.ReturnValues == types.ReturnValueAllOld {
+		output.Attributes = old
+	}
+	return output, nil
+}
+
+// UpdateItem applies the update expression to the item with the provided key, creating it when it doesn't exist, once the condition expression holds
+func (f *Fake) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := t.keyID(params.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	old := t.items[id]
+	ectx := &exprContext{names: params.ExpressionAttributeNames, values: params.ExpressionAttributeValues}
+	if err := checkCondition(aws.ToString(params.ConditionExpression), ectx, old, params.ReturnValuesOnConditionCheckFailure); err != nil {
+		return nil, err
+	}
+
+	item := maps.Clone(old)
+	if item == nil {
+		item = maps.Clone(params.Key)
+	}
+
+	var touched []string
+	if expr := aws.ToString(params.UpdateExpression); expr != "" {
+		upd, err := parseUpdate(expr, ectx)
+		if err != nil {
+			return nil, validation(err)
+		}
+		if touched, err = upd(item); err != nil {
+			return nil, validation(err)
+		}
+	}
+
+	for name := range params.Key {
+		if !equal(item[name], params.Key[name]) {
+			return nil, validation(fmt.Errorf("cannot update attribute %s, it is part of the key", name))
+		}
+	}
+
+	t.items[id] = item
+
+	output := &dynamodb.UpdateItemOutput{}
+	switch params.ReturnValues {
+	case types.ReturnValueAllNew:
+		output.Attributes = maps.Clone(item)
+	case types.ReturnValueAllOld:
+		output.Attributes = old
+	case types.ReturnValueUpdatedNew, types.ReturnValueUpdatedOld:
+		src := item
+		if params.ReturnValues == types.ReturnValueUpdatedOld {
+			src = old
+		}
+		output.Attributes = make(map[string]types.AttributeValue)
+		for _, name := range touched {
+			if v, ok := src[name]; ok {
+				output.Attributes[name] = v
+			}
+		}
+	}
+	return output, nil
+}
+
+// Query returns the items of the table or index matching the key condition, sorted by sort key and paged by Limit or PageSize, with the filter applied after each page is read
+func (f *Fake) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	ectx := &exprContext{names: params.ExpressionAttributeNames, values: params.ExpressionAttributeValues}
+	keyCond, err := parseCondition(aws.ToString(params.KeyConditionExpression), ectx)
+	if err != nil {
+		return nil, validation(err)
+	}
+
+	reverse := params.ScanIndexForward != nil && !*params.ScanIndexForward
+	p, err := t.page(aws.ToString(params.IndexName), keyCond, reverse, params.ExclusiveStartKey, f.limit(params.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	items, count, err := finishPage(p.items, aws.ToString(params.FilterExpression), aws.ToString(params.ProjectionExpression), params.Select, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.QueryOutput{
+		Items:            items,
+		Count:            int32(count),
+		ScannedCount:     int32(len(p.items)),
+		LastEvaluatedKey: p.lastKey,
+	}, nil
+}
+
+// Scan returns the items of the table or index in primary key order, paged by Limit or PageSize, with the filter applied after each page is read
+func (f *Fake) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t, err := f.table(params.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	ectx := &exprContext{names: params.ExpressionAttributeNames, values: params.ExpressionAttributeValues}
+	inSegment := func(map[string]types.AttributeValue) bool { return true }
+	if total := aws.ToInt32(params.TotalSegments); total > 0 {
+		segment := uint32(aws.ToInt32(params.Segment))
+		inSegment = func(item map[string]types.AttributeValue) bool {
+			return t.segment(item, uint32(total)) == segment
+		}
+	}
+
+	p, err := t.page(aws.ToString(params.IndexName), inSegment, false, params.ExclusiveStartKey, f.limit(params.Limit))
+	if err != nil {
+		return nil, err
+	}
+
+	items, count, err := finishPage(p.items, aws.ToString(params.FilterExpression), aws.ToString(params.ProjectionExpression), params.Select, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynamodb.ScanOutput{
+		Items:            items,
+		Count:            int32(count),
+		ScannedCount:     int32(len(p.items)),
+		LastEvaluatedKey: p.lastKey,
+	}, nil
+}
+
+// BatchGetItem returns the items for every requested key, it never leaves keys unprocessed
+func (f *Fake) BatchGetItem(ctx context.Context, params *dynamodb.BatchGetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchGetItemOutput, error) {
+	output := &dynamodb.BatchGetItemOutput{Responses: make(map[string][]map[string]types.AttributeValue)}
+
+	for tableName, req := range params.RequestItems {
+		for _, key := range req.Keys {
+			got, err := f.GetItem(ctx, &dynamodb.GetItemInput{
+				TableName:                aws.String(tableName),
+				Key:                      key,
+				ProjectionExpression:     req.ProjectionExpression,
+				ExpressionAttributeNames: req.ExpressionAttributeNames,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if got.Item != nil {
+				output.Responses[tableName] = append(output.Responses[tableName], got.Item)
+			}
+		}
+	}
+
+	return output, nil
+}
+
+// BatchWriteItem applies every put and delete request, it never leaves items unprocessed
+func (f *Fake) BatchWriteItem(ctx context.Context, params *dynamodb.BatchWriteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.BatchWriteItemOutput, error) {
+	for tableName, writes := range params.RequestItems {
+		for _, w := range writes {
+			var err error
+			switch {
+			case w.PutRequest != nil:
+				_, err = f.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(tableName), Item: w.PutRequest.Item})
+			case w.DeleteRequest != nil:
+				_, err = f.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(tableName), Key: w.DeleteRequest.Key})
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+// TransactWriteItems is not supported by Fake
+func (f *Fake) TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	return nil, unsupported("TransactWriteItems")
+}
+
+// UpdateTable is not supported by Fake
+func (f *Fake) UpdateTable(ctx context.Context, params *dynamodb.UpdateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTableOutput, error) {
+	return nil, unsupported("UpdateTable")
+}
+
+// DescribeContinuousBackups is not supported by Fake
+func (f *Fake) DescribeContinuousBackups(ctx context.Context, params *dynamodb.DescribeContinuousBackupsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeContinuousBackupsOutput, error) {
+	return nil, unsupported("DescribeContinuousBackups")
+}
+
+// UpdateContinuousBackups is not supported by Fake
+func (f *Fake) UpdateContinuousBackups(ctx context.Context, params *dynamodb.UpdateContinuousBackupsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateContinuousBackupsOutput, error) {
+	return nil, unsupported("UpdateContinuousBackups")
+}
+
+// CreateBackup is not supported by Fake
+func (f *Fake) CreateBackup(ctx context.Context, params *dynamodb.CreateBackupInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateBackupOutput, error) {
+	return nil, unsupported("CreateBackup")
+}
+
+// RestoreTableFromBackup is not supported by Fake
+func (f *Fake) RestoreTableFromBackup(ctx context.Context, params *dynamodb.RestoreTableFromBackupInput, optFns ...func(*dynamodb.Options)) (*dynamodb.RestoreTableFromBackupOutput, error) {
+	return nil, unsupported("RestoreTableFromBackup")
+}
+
+// TagResource is not supported by Fake
+func (f *Fake) TagResource(ctx context.Context, params *dynamodb.TagResourceInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TagResourceOutput, error) {
+	return nil, unsupported("TagResource")
+}
+
+// UntagResource is not supported by Fake
+func (f *Fake) UntagResource(ctx context.Context, params *dynamodb.UntagResourceInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UntagResourceOutput, error) {
+	return nil, unsupported("UntagResource")
+}
+
+// ListTagsOfResource is not supported by Fake
+func (f *Fake) ListTagsOfResource(ctx context.Context, params *dynamodb.ListTagsOfResourceInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ListTagsOfResourceOutput, error) {
+	return nil, unsupported("ListTagsOfResource")
+}
+
+// table returns the named table or a ResourceNotFoundException, f.mu must be held
+func (f *Fake) table(name *string) (*table, error) {
+	t, ok := f.tables[aws.ToString(name)]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("Requested resource not found: Table: " + aws.ToString(name) + " not found")}
+	}
+	return t, nil
+}
+
+// limit returns the number of items a page may evaluate, 0 meaning no limit
+func (f *Fake) limit(limit *int32) int {
+	if limit != nil {
+		return int(*limit)
+	}
+	return f.PageSize
+}
+
+// keyID returns the string identifying the item with the provided key, the key must hold exactly the table key attributes
+func (t *table) keyID(key map[string]types.AttributeValue) (string, error) {
+	names := []string{t.hashKey}
+	if t.sortKey != "" {
+		names = append(names, t.sortKey)
+	}
+
+	var sb strings.Builder
+	for _, name := range names {
+		v, ok := key[name]
+		if !ok {
+			return "", validation(fmt.Errorf("the provided key element %s is missing", name))
+		}
+		s, err := scalarString(v)
+		if err != nil {
+			return "", validation(fmt.Errorf("key attribute %s: %w", name, err))
+		}
+		sb.WriteString(s)
+		sb.WriteByte(0)
+	}
+
+	return sb.String(), nil
+}
+
+// segment returns the parallel scan segment of item, items are spread across segments by a hash of their partition key like dynamo does
+func (t *table) segment(item map[string]types.AttributeValue, total uint32) uint32 {
+	s, _ := scalarString(item[t.hashKey])
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32() % total
+}
+
+// tableKey returns the primary key attributes of item
+func (t *table) tableKey(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := map[string]types.AttributeValue{t.hashKey: item[t.hashKey]}
+	if t.sortKey != "" {
+		key[t.sortKey] = item[t.sortKey]
+	}
+	return key
+}
+
+// page is the items evaluated for a Query or Scan page and the key to continue from
+type page struct {
+	items   []map[string]types.AttributeValue
+	lastKey map[string]types.AttributeValue
+}
+
+// page selects the items of the table or index matching keyCond in sort order, starting after startKey and evaluating at most limit items
+func (t *table) page(indexName string, keyCond condition, reverse bool, startKey map[string]types.AttributeValue, limit int) (*page, error) {
+	hash, sortKey := t.hashKey, t.sortKey
+	if indexName != "" {
+		keys, ok := t.indexes[indexName]
+		if !ok {
+			return nil, validation(fmt.Errorf("the table does not have the specified index: %s", indexName))
+		}
+		hash, sortKey = keys[0], keys[1]
+	}
+
+	candidates := make([]map[string]types.AttributeValue, 0, len(t.items))
+	for _, item := range t.items {
+		if item[hash] == nil || (sortKey != "" && item[sortKey] == nil) {
+			// items missing an index key are not in the index
+			continue
+		}
+		if keyCond(item) {
+			candidates = append(candidates, item)
+		}
+	}
+
+	less := func(a, b map[string]types.AttributeValue) bool {
+		for _, name := range []string{hash, sortKey} {
+			if name == "" {
+				continue
+			}
+			if c, _ := compareKeys(a[name], b[name]); c != 0 {
+				return c < 0
+			}
+		}
+		ka, _ := t.keyID(a)
+		kb, _ := t.keyID(b)
+		return ka < kb
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if reverse {
+			return less(candidates[j], candidates[i])
+		}
+		return less(candidates[i], candidates[j])
+	})
+
+	start := 0
+	if startKey != nil {
+		start = sort.Search(len(candidates), func(i int) bool {
+			if reverse {
+				return less(candidates[i], startKey)
+			}
+			return less(startKey, candidates[i])
+		})
+	}
+
+	end := len(candidates)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+
+	p := &page{items: candidates[start:end]}
+	if end < len(candidates) && end > start {
+		last := candidates[end-1]
+		p.lastKey = t.tableKey(last)
+		if indexName != "" {
+			p.lastKey[hash] = last[hash]
+			if sortKey != "" {
+				p.lastKey[sortKey] = last[sortKey]
+			}
+		}
+	}
+
+	return p, nil
+}
+
+// finishPage applies the filter and projection to the evaluated items and returns them with their count, items are copies so callers can't mutate the stored ones. Select COUNT only returns the count
+func finishPage(evaluated []map[string]types.AttributeValue, filterExpr, projExpr string, sel types.Select, ectx *exprContext) ([]map[string]types.AttributeValue, int, error) {
+	filter, err := parseCondition(filterExpr, ectx)
+	if err != nil {
+		return nil, 0, validation(err)
+	}
+
+	items := make([]map[string]types.AttributeValue, 0, len(evaluated))
+	for _, item := range evaluated {
+		if !filter(item) {
+			continue
+		}
+		projected, err := project(item, projExpr, ectx)
+		if err != nil {
+			return nil, 0, validation(err)
+		}
+		items = append(items, projected)
+	}
+
+	if sel == types.SelectCount {
+		return nil, len(items), nil
+	}
+
+	return items, len(items), nil
+}
+
+// project copies item keeping only the attributes named by the projection expression, or all of them when it is empty
+func project(item map[string]types.AttributeValue, expr string, ectx *exprContext) (map[string]types.AttributeValue, error) {
+	if expr == "" {
+		return maps.Clone(item), nil
+	}
+
+	names, err := parseProjection(expr, ectx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]types.AttributeValue, len(names))
+	for _, name := range names {
+		if v, ok := item[name]; ok {
+			out[name] = v
+		}
+	}
+	return out, nil
+}
+
+// checkCondition evaluates a condition expression against the current item, returning a ConditionalCheckFailedException when it doesn't hold
+func checkCondition(expr string, ectx *exprContext, current map[string]types.AttributeValue, onFailure types.ReturnValuesOnConditionCheckFailure) error {
+	if expr == "" {
+		return nil
+	}
+
+	cond, err := parseCondition(expr, ectx)
+	if err != nil {
+		return validation(err)
+	}
+
+	if cond(current) {
+		return nil
+	}
+
+	e := &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	if onFailure == types.ReturnValuesOnConditionCheckFailureAllOld {
+		e.Item = maps.Clone(current)
+	}
+	return e
+}
+
+func keyNames(schema []types.KeySchemaElement) (hash, sort string) {
+	for _, k := range schema {
+		switch k.KeyType {
+		case types.KeyTypeHash:
+			hash = aws.ToString(k.AttributeName)
+		case types.KeyTypeRange:
+			sort = aws.ToString(k.AttributeName)
+		}
+	}
+	return hash, sort
+}
+
+// scalarString returns a string form of a key value that is distinct across types
+func scalarString(v types.AttributeValue) (string, error) {
+	switch x := v.(type) {
+	case *types.AttributeValueMemberS:
+		return "S" + x.Value, nil
+	case *types.AttributeValueMemberN:
+		n, ok := parseNumber(x.Value)
+		if !ok {
+			return "", fmt.Errorf("invalid number %q", x.Value)
+		}
+		return "N" + n, nil
+	case *types.AttributeValueMemberB:
+		return "B" + string(x.Value), nil
+	default:
+		return "", fmt.Errorf("key attributes must be S, N or B, got %T", v)
+	}
+}
+
+// compareKeys orders two key values, values that can't be compared sort by type
+func compareKeys(a, b types.AttributeValue) (int, bool) {
+	if c, ok := compare(a, b); ok {
+		return c, true
+	}
+	return strings.Compare(fmt.Sprintf("%T", a), fmt.Sprintf("%T", b)), false
+}
+
+// parseNumber normalizes a number so equal values written differently, like 1 and 1.0, produce the same string
+func parseNumber(s string) (string, bool) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return "", false
+	}
+	return formatRat(r), true
+}
+
+func unsupported(op string) error {
+	return &smithy.GenericAPIError{Code: "UnsupportedOperation", Message: op + " is not supported by ddbtest.Fake", Fault: smithy.FaultClient}
+}
+
+func validation(err error) error {
+	return &smithy.GenericAPIError{Code: "ValidationException", Message: err.Error(), Fault: smithy.FaultClient}
+}
@@ -0,0 +1,122 @@
+package ddbtest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go"
+)
+
+// newTestFake returns a Fake with a "pk"/"sk" table named "t" holding sort keys 1 to 5 under "a", each with "n" set to its sort key
+func newTestFake(t *testing.T) *Fake {
+	t.Helper()
+
+	f := NewFake()
+	f.AddTable("t", "pk", "sk")
+	for _, sk := range []string{"1", "2", "3", "4", "5"} {
+		item := map[string]types.AttributeValue{"pk": s("a"), "sk": s(sk), "n": n(sk)}
+		if _, err := f.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("t"), Item: item}); err != nil {
+			t.Fatalf("put %s: %v", sk, err)
+		}
+	}
+	return f
+}
+
+func TestFakeQueryPagesBeforeFiltering(t *testing.T) {
+	f := newTestFake(t)
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String("t"),
+		KeyConditionExpression:    aws.String("pk = :pk AND sk >= :from"),
+		FilterExpression:          aws.String("n <> :skip"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":pk": s("a"), ":from": s("2"), ":skip": n("3")},
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int32(2),
+	}
+
+	var got []string
+	var scanned int32
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("paging didn't stop, got %v", got)
+		}
+		output, err := f.Query(context.Background(), input)
+		if err != nil {
+			t.Fatalf("query: %v", err)
+		}
+		scanned += output.ScannedCount
+		for _, item := range output.Items {
+			got = append(got, item["sk"].(*types.AttributeValueMemberS).Value)
+		}
+		if output.LastEvaluatedKey == nil {
+			break
+		}
+		input.ExclusiveStartKey = output.LastEvaluatedKey
+	}
+
+	if want := []string{"5", "4", "2"}; !slices.Equal(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if scanned != 4 {
+		t.Fatalf("expected the filtered item to count as scanned, got %d", scanned)
+	}
+}
+
+func TestFakeUpdateItemConditionAndKeys(t *testing.T) {
+	f := newTestFake(t)
+	key := map[string]types.AttributeValue{"pk": s("a"), "sk": s("1")}
+
+	_, err := f.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:                           aws.String("t"),
+		Key:                                 key,
+		UpdateExpression:                    aws.String("SET n = n + :one"),
+		ConditionExpression:                 aws.String("n > :one"),
+		ExpressionAttributeValues:           map[string]types.AttributeValue{":one": n("1")},
+		ReturnValuesOnConditionCheckFailure: types.ReturnValuesOnConditionCheckFailureAllOld,
+	})
+	var ccf *types.ConditionalCheckFailedException
+	if !errors.As(err, &ccf) {
+		t.Fatalf("expected ConditionalCheckFailedException, got %v", err)
+	}
+	if v, ok := ccf.Item["n"].(*types.AttributeValueMemberN); !ok || v.Value != "1" {
+		t.Fatalf("expected the current item on the exception, got %v", ccf.Item)
+	}
+
+	_, err = f.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("t"),
+		Key:                       key,
+		UpdateExpression:          aws.String("SET sk = :sk"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":sk": s("9")},
+	})
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "ValidationException" {
+		t.Fatalf("expected a ValidationException updating a key attribute, got %v", err)
+	}
+
+	output, err := f.UpdateItem(context.Background(), &dynamodb.UpdateItemInput{
+		TableName:                 aws.String("t"),
+		Key:                       key,
+		UpdateExpression:          aws.String("SET n = n + :one REMOVE missing"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{":one": n("1")},
+		ReturnValues:              types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if v, ok := output.Attributes["n"].(*types.AttributeValueMemberN); len(output.Attributes) != 1 || !ok || v.Value != "2" {
+		t.Fatalf("expected only the updated n=2, got %v", output.Attributes)
+	}
+}
+
+func TestFakeUnsupportedOperation(t *testing.T) {
+	_, err := NewFake().TransactWriteItems(context.Background(), &dynamodb.TransactWriteItemsInput{})
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "UnsupportedOperation" {
+		t.Fatalf("expected UnsupportedOperation, got %v", err)
+	}
+}
@@ -0,0 +1,33 @@
+package ddb
+
+import (
+	"maps"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// WithDerivedKeys runs fn on every item written by PutItem, and the helpers built on it, BatchPutStructs and TxBuilder.Put, and merges the attributes it returns into the item before it is sent.
+// it is the one place to compute single table design index keys like GSI1PK = "USER#123" from other attributes, a returned attribute replaces one the item already has and the caller's item is never modified
+func WithDerivedKeys(fn func(item map[string]types.AttributeValue) map[string]types.AttributeValue) Option {
+	return func(d *DynamoDB) {
+		d.derivedKeys = fn
+	}
+}
+
+// withDerivedKeys returns item with the derived attributes merged into a copy, or item itself when WithDerivedKeys isn't used
+func (d *DynamoDB) withDerivedKeys(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if d.derivedKeys == nil {
+		return item
+	}
+
+	derived := d.derivedKeys(item)
+	if len(derived) == 0 {
+		return item
+	}
+
+	out := make(map[string]types.AttributeValue, len(item)+len(derived))
+	maps.Copy(out, item)
+	maps.Copy(out, derived)
+
+	return out
+}
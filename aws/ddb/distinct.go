@@ -0,0 +1,112 @@
+package ddb
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CountDistinctPartitionKeys returns how many distinct values of the partition key pkName exist in the table.
+// it is a full table scan projecting only pkName, so it consumes read capacity for every item, and every distinct key is held in memory. use CountDistinctPartitionKeysApprox for tables with too many keys to hold
+func (d *DynamoDB) CountDistinctPartitionKeys(ctx context.Context, tableName, pkName string) (int, error) {
+	seen := make(map[string]struct{})
+
+	err := d.scanPartitionKeys(ctx, tableName, pkName, func(id string) {
+		seen[id] = struct{}{}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(seen), nil
+}
+
+// CountDistinctPartitionKeysApprox is like CountDistinctPartitionKeys but tracks seen keys in a bloom filter sized for expectedKeys at falsePositiveRate, bounding memory regardless of the table size.
+// a false positive makes a new key look already seen, so the result can only undercount, by roughly falsePositiveRate once the table holds about expectedKeys distinct keys
+func (d *DynamoDB) CountDistinctPartitionKeysApprox(ctx context.Context, tableName, pkName string, expectedKeys int, falsePositiveRate float64) (int, error) {
+	filter := newBloomFilter(expectedKeys, falsePositiveRate)
+
+	var count int
+	err := d.scanPartitionKeys(ctx, tableName, pkName, func(id string) {
+		if !filter.testAndAdd(id) {
+			count++
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// scanPartitionKeys scans the table projecting only pkName and calls fn with the string form of every partition key value
+func (d *DynamoDB) scanPartitionKeys(ctx context.Context, tableName, pkName string, fn func(id string)) error {
+	names, aliases := ExprNames(pkName)
+
+	return d.ScanPages(ctx, &dynamodb.ScanInput{
+		TableName:                aws.String(tableName),
+		ProjectionExpression:     aws.String(aliases[0]),
+		ExpressionAttributeNames: names,
+	}, func(page []map[string]types.AttributeValue) error {
+		for _, item := range page {
+			fn(keyString(map[string]types.AttributeValue{pkName: item[pkName]}))
+		}
+		return nil
+	})
+}
+
+// bloomFilter is a fixed size set membership filter with no false negatives
+type bloomFilter struct {
+	bits   []uint64
+	m      uint64
+	hashes int
+}
+
+// newBloomFilter sizes a filter for n elements at false positive rate p
+func newBloomFilter(n int, p float64) *bloomFilter {
+	n = max(n, 1)
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	m = max(m, 64)
+	k := max(1, int(math.Round(float64(m)/float64(n)*math.Ln2)))
+
+	return &bloomFilter{
+		bits:   make([]uint64, (m+63)/64),
+		m:      m,
+		hashes: k,
+	}
+}
+
+// testAndAdd adds s to the filter and reports whether it was possibly already present
+func (b *bloomFilter) testAndAdd(s string) bool {
+	h := fnv.New128a()
+	h.Write([]byte(s))
+	sum := h.Sum(nil)
+
+	// double hashing, derive every position from two independent 64 bit halves
+	h1 := uint64(0)
+	h2 := uint64(0)
+	for i := 0; i < 8; i++ {
+		h1 = h1<<8 | uint64(sum[i])
+		h2 = h2<<8 | uint64(sum[8+i])
+	}
+
+	present := true
+	for i := 0; i < b.hashes; i++ {
+		pos := (h1 + uint64(i)*h2) % b.m
+		word, bit := pos/64, uint64(1)<<(pos%64)
+		if b.bits[word]&bit == 0 {
+			present = false
+			b.bits[word] |= bit
+		}
+	}
+
+	return present
+}
@@ -0,0 +1,86 @@
+package ddb
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// DryRunOp describes a write that was skipped because the wrapper is in dry-run mode
+type DryRunOp struct {
+	// Operation is the dynamo API call that would have been made, e.g. PutItem or BatchWriteItem
+	Operation string
+	TableName string
+	// Items is how many items the call would have written or deleted
+	Items int
+	// Input is the request that would have been sent
+	Input any
+}
+
+type dryRunLog struct {
+	mu  sync.Mutex
+	ops []DryRunOp
+}
+
+// WithDryRun makes PutItem, UpdateItem, DeleteItem, BatchWriteItem and the batch write helpers record the operation instead of sending it.
+// reads still hit dynamo so the dry run sees real data, the recorded operations are available through DryRunOps
+func WithDryRun() Option {
+	return func(d *DynamoDB) {
+		d.dryRun = &dryRunLog{}
+	}
+}
+
+// DryRunOps returns the writes recorded so far in dry-run mode, nil when dry-run is disabled
+func (d *DynamoDB) DryRunOps() []DryRunOp {
+	if d.dryRun == nil {
+		return nil
+	}
+
+	d.dryRun.mu.Lock()
+	defer d.dryRun.mu.Unlock()
+
+	ops := make([]DryRunOp, len(d.dryRun.ops))
+	copy(ops, d.dryRun.ops)
+
+	return ops
+}
+
+// DryRunCounts returns how many items each operation would have written or deleted in dry-run mode
+func (d *DynamoDB) DryRunCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, op := range d.DryRunOps() {
+		counts[op.Operation] += op.Items
+	}
+	return counts
+}
+
+// ResetDryRun clears the recorded dry-run operations
+func (d *DynamoDB) ResetDryRun() {
+	if d.dryRun == nil {
+		return
+	}
+
+	d.dryRun.mu.Lock()
+	defer d.dryRun.mu.Unlock()
+
+	d.dryRun.ops = nil
+}
+
+// recordDryRun records the operation and reports true when the wrapper is in dry-run mode and the write must be skipped
+func (d *DynamoDB) recordDryRun(operation string, tableName *string, items int, input any) bool {
+	if d.dryRun == nil {
+		return false
+	}
+
+	d.dryRun.mu.Lock()
+	defer d.dryRun.mu.Unlock()
+
+	d.dryRun.ops = append(d.dryRun.ops, DryRunOp{
+		Operation: operation,
+		TableName: aws.ToString(tableName),
+		Items:     items,
+		Input:     input,
+	})
+
+	return true
+}
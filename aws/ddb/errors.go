@@ -0,0 +1,39 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+)
+
+// isThrottle reports whether err is dynamo rejecting the request for exceeding capacity or request limits
+func isThrottle(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	_, ok := retry.DefaultThrottleErrorCodes[apiErr.ErrorCode()]
+	return ok
+}
+
+// isServerFailure reports whether err points at dynamo being unhealthy rather than at the request, throttling and server faults count, as do transport errors.
+// client errors like a failed condition or a validation error mean dynamo answered fine, and a cancelled context is the caller's own doing
+func isServerFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if isThrottle(err) {
+		return true
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorFault() == smithy.FaultServer
+	}
+
+	return true
+}
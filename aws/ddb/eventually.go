@@ -0,0 +1,43 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// defaultEventuallyPollInterval is how often QueryOneEventually retries when the caller passes no poll interval
+const defaultEventuallyPollInterval = 250 * time.Millisecond
+
+// QueryOneEventually runs QueryOne every pollInterval until it matches an item, bridging the propagation delay of global secondary indexes right after a write, mostly in integration tests.
+// pollInterval <= 0 polls every 250ms rather than back to back. when no item shows up within timeout, or ctx is done first, ErrItemNotVisible is returned.
+// any error other than ErrNotFound stops polling and is returned as is
+func (d *DynamoDB) QueryOneEventually(ctx context.Context, input *dynamodb.QueryInput, timeout time.Duration, pollInterval time.Duration) (map[string]types.AttributeValue, error) {
+	if pollInterval <= 0 {
+		pollInterval = defaultEventuallyPollInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		item, err := d.QueryOne(ctx, input)
+		if err == nil {
+			return item, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("%w: %w", ErrItemNotVisible, ctx.Err())
+			}
+			return nil, err
+		}
+
+		if err := sleep(ctx, pollInterval); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrItemNotVisible, err)
+		}
+	}
+}
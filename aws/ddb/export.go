@@ -0,0 +1,114 @@
+package ddb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ExportJSONL scans the table and writes every item to w as one JSON object per line, returning the number of items written.
+// items are streamed page by page so the table is never fully held in memory, and a write error on w stops the scan before the next page is fetched
+func (d *DynamoDB) ExportJSONL(ctx context.Context, input *dynamodb.ScanInput, w io.Writer) (_ int, err error) {
+	ctx, span := d.startSpan(ctx, "ExportJSONL", input.TableName)
+	defer func() { endSpan(span, err) }()
+
+	enc := json.NewEncoder(w)
+	it := d.NewScanIterator(input)
+
+	var count int
+	for it.Next(ctx) {
+		m, err := ToPlainMap(it.Item())
+		if err != nil {
+			return count, fmt.Errorf("error converting item %d: %w", count, err)
+		}
+
+		if err := enc.Encode(m); err != nil {
+			return count, fmt.Errorf("error writing item %d: %w", count, err)
+		}
+		count++
+	}
+
+	if err := it.Err(); err != nil {
+		return count, fmt.Errorf("error scanning dynamo: %w", err)
+	}
+
+	return count, nil
+}
+
+// ExportCSV runs the query and writes the matching items to w as CSV with a header row of columns and one row per item, returning the number of items written.
+// strings and numbers are written as is, binary as base64, booleans as true or false and sets, lists and maps as JSON, a missing or NULL attribute is an empty cell.
+// rows are written page by page as the query is read so the results are never fully held in memory
+func (d *DynamoDB) ExportCSV(ctx context.Context, input *dynamodb.QueryInput, columns []string, w io.Writer) (_ int, err error) {
+	ctx, span := d.startSpan(ctx, "ExportCSV", input.TableName)
+	defer func() { endSpan(span, err) }()
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return 0, fmt.Errorf("error writing header: %w", err)
+	}
+
+	var count int
+	record := make([]string, len(columns))
+	err = d.QueryPages(ctx, input, func(page []map[string]types.AttributeValue) error {
+		for _, item := range page {
+			for i, col := range columns {
+				cell, err := csvCell(item[col])
+				if err != nil {
+					return fmt.Errorf("error converting item %d attribute %s: %w", count, col, err)
+				}
+				record[i] = cell
+			}
+
+			if err := cw.Write(record); err != nil {
+				return fmt.Errorf("error writing item %d: %w", count, err)
+			}
+			count++
+		}
+
+		cw.Flush()
+		return cw.Error()
+	})
+	if err != nil {
+		return count, err
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return count, fmt.Errorf("error writing csv: %w", err)
+	}
+
+	return count, nil
+}
+
+// csvCell formats an attribute value as a CSV cell
+func csvCell(av types.AttributeValue) (string, error) {
+	switch v := av.(type) {
+	case nil, *types.AttributeValueMemberNULL:
+		return "", nil
+	case *types.AttributeValueMemberS:
+		return v.Value, nil
+	case *types.AttributeValueMemberN:
+		return v.Value, nil
+	case *types.AttributeValueMemberB:
+		return base64.StdEncoding.EncodeToString(v.Value), nil
+	case *types.AttributeValueMemberBOOL:
+		return strconv.FormatBool(v.Value), nil
+	default:
+		pv, err := toPlainValue(av)
+		if err != nil {
+			return "", err
+		}
+		b, err := json.Marshal(pv)
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	}
+}
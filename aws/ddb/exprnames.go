@@ -0,0 +1,32 @@
+package ddb
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ExprNames aliases every attribute name as #n0, #n1... so reserved words like "name" or "status" are safe to use in expressions.
+// it returns the map to pass as ExpressionAttributeNames and the alias of each name in the order they were provided
+func ExprNames(names ...string) (map[string]string, []string) {
+	m := make(map[string]string, len(names))
+	aliases := make([]string, len(names))
+	for i, name := range names {
+		alias := fmt.Sprintf("#n%d", i)
+		m[alias] = name
+		aliases[i] = alias
+	}
+	return m, aliases
+}
+
+// ExprValues assigns every value a placeholder :v0, :v1... returning the map to pass as ExpressionAttributeValues and the placeholder of each value in the order they were provided
+func ExprValues(values ...types.AttributeValue) (map[string]types.AttributeValue, []string) {
+	m := make(map[string]types.AttributeValue, len(values))
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		placeholder := fmt.Sprintf(":v%d", i)
+		m[placeholder] = v
+		placeholders[i] = placeholder
+	}
+	return m, placeholders
+}
@@ -0,0 +1,117 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ScanWithFilter is a wrapper around ScanAll that compiles the filter with the expression builder and returns every item of the table matching it
+func (d *DynamoDB) ScanWithFilter(ctx context.Context, tableName string, filter expression.ConditionBuilder) ([]map[string]types.AttributeValue, error) {
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building filter: %w", err)
+	}
+
+	return d.ScanAll(ctx, &dynamodb.ScanInput{
+		TableName:                 aws.String(tableName),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+}
+
+// ScanAllFiltered is a wrapper around ScanWithFilter that unmarshals every item of the table matching the filter into T, the compiled names and values are sent with every page of the scan
+func ScanAllFiltered[T any](ctx context.Context, d *DynamoDB, tableName string, filter expression.ConditionBuilder) ([]T, error) {
+	items, err := d.ScanWithFilter(ctx, tableName, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalItems[T](d, items)
+}
+
+// QueryWithFilter is a wrapper around QueryAll that compiles the key condition and filter with the expression builder and returns every matching item
+func (d *DynamoDB) QueryWithFilter(ctx context.Context, tableName string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder) ([]map[string]types.AttributeValue, error) {
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).WithFilter(filter).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building expression: %w", err)
+	}
+
+	return d.QueryAll(ctx, &dynamodb.QueryInput{
+		TableName:                 aws.String(tableName),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+}
+
+// ScanCountWithFilter returns how many items of the table match the filter without retrieving them, paging a Select COUNT scan to completion.
+// the result is the post-filter Count, not ScannedCount, the scan still consumes read capacity for every item it evaluates
+func (d *DynamoDB) ScanCountWithFilter(ctx context.Context, tableName string, filter expression.ConditionBuilder) (int, error) {
+	expr, err := expression.NewBuilder().WithFilter(filter).Build()
+	if err != nil {
+		return 0, fmt.Errorf("error building filter: %w", err)
+	}
+
+	return d.scanCount(ctx, &dynamodb.ScanInput{
+		TableName:                 aws.String(tableName),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+}
+
+// QueryWithFilterPaginated runs the query with the filter applied and returns the ops.Skip / ops.Limit window of the matching items unmarshalled into T, see PaginationOps, with Count being the number of items that pass the filter.
+// filters are applied by dynamo after items are read, so the whole key condition is paged through in a single pass to compute the count and the window: every item the key condition matches consumes read capacity,
+// including the ones the filter drops and the ones outside the window. only the items inside the window are kept in memory
+func QueryWithFilterPaginated[T any](ctx context.Context, d *DynamoDB, tableName string, keyCond expression.KeyConditionBuilder, filter expression.ConditionBuilder, ops PaginationOps) (*PaginatedResultsOf[T], error) {
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).WithFilter(filter).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building expression: %w", err)
+	}
+
+	input := ops.QueryInput
+	input.TableName = aws.String(tableName)
+	input.KeyConditionExpression = expr.KeyCondition()
+	input.FilterExpression = expr.Filter()
+	input.ExpressionAttributeNames = expr.Names()
+	input.ExpressionAttributeValues = expr.Values()
+	input.ExclusiveStartKey = nil
+
+	var count int
+	window := make([]map[string]types.AttributeValue, 0)
+	err = d.QueryPages(ctx, &input, func(page []map[string]types.AttributeValue) error {
+		for _, item := range page {
+			if count >= ops.Skip && len(window) < ops.Limit {
+				window = append(window, item)
+			}
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error querying dynamo: %w", err)
+	}
+
+	items, err := unmarshalItems[T](d, window)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages, page := pageNumbers(ops.Skip, ops.Limit, count)
+
+	return &PaginatedResultsOf[T]{
+		Items:      items,
+		Skip:       ops.Skip,
+		Limit:      ops.Limit,
+		Count:      count,
+		TotalPages: totalPages,
+		Page:       page,
+	}, nil
+}
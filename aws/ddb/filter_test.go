@@ -0,0 +1,87 @@
+package ddb_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+)
+
+// putSparse writes n items under pk, only every tenth one carries the "keep" attribute the tests filter on
+func putSparse(t *testing.T, d *ddb.DynamoDB, pk string, n int) (kept []string) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		item := key(pk, fmt.Sprintf("%03d", i))
+		if i%10 == 0 {
+			item["keep"] = &types.AttributeValueMemberBOOL{Value: true}
+			kept = append(kept, fmt.Sprintf("%03d", i))
+		}
+		if _, err := d.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("t"), Item: item}); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+	}
+
+	return kept
+}
+
+func TestQueryWithFilterPaginatedSparseFilter(t *testing.T) {
+	d, f := newFake(t)
+	// every page holds at most one item passing the filter and most hold none
+	f.PageSize = 4
+	kept := putSparse(t, d, "a", 95)
+	putSparse(t, d, "b", 20)
+
+	keyCond := expression.Key("pk").Equal(expression.Value("a"))
+	filter := expression.AttributeExists(expression.Name("keep"))
+
+	cases := []struct {
+		skip, limit int
+		want        []string
+		page        int
+	}{
+		{skip: 0, limit: 3, want: kept[0:3], page: 0},
+		{skip: 3, limit: 3, want: kept[3:6], page: 1},
+		{skip: 9, limit: 3, want: kept[9:10], page: 3},
+		{skip: 12, limit: 3, want: nil, page: 4},
+	}
+
+	for _, c := range cases {
+		res, err := ddb.QueryWithFilterPaginated[row](context.Background(), d, "t", keyCond, filter, ddb.PaginationOps{Skip: c.skip, Limit: c.limit})
+		if err != nil {
+			t.Fatalf("skip %d: %v", c.skip, err)
+		}
+
+		if got := sortKeys(res.Items); !slices.Equal(got, c.want) {
+			t.Fatalf("skip %d: expected %v, got %v", c.skip, c.want, got)
+		}
+		if res.Count != len(kept) || res.TotalPages != 4 || res.Page != c.page {
+			t.Fatalf("skip %d: expected count %d, 4 pages and page %d, got %d, %d and %d", c.skip, len(kept), c.page, res.Count, res.TotalPages, res.Page)
+		}
+	}
+}
+
+func TestQueryWithFilterPaginatedFilterDropsEverything(t *testing.T) {
+	d, f := newFake(t)
+	f.PageSize = 4
+	putSparse(t, d, "a", 40)
+
+	res, err := ddb.QueryWithFilterPaginated[row](context.Background(), d, "t",
+		expression.Key("pk").Equal(expression.Value("a")),
+		expression.AttributeExists(expression.Name("missing")),
+		ddb.PaginationOps{Limit: 5},
+	)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	if len(res.Items) != 0 || res.Count != 0 || res.TotalPages != 0 {
+		t.Fatalf("expected no items, count or pages, got %+v", res)
+	}
+}
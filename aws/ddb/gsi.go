@@ -0,0 +1,97 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// gsiPollInterval is how often CreateGSI checks on an index that is still being created or backfilled
+const gsiPollInterval = 10 * time.Second
+
+// CreateGSI adds the global secondary index described by gsi.Create to the table, when wait is set it blocks until the index is ACTIVE and done backfilling.
+// key attributes already defined on the table are looked up from DescribeTable, new ones must be passed in defs. if the context expires while waiting ErrBackfillTimeout is returned, the index keeps being built by dynamo.
+// ErrIndexNotFound is returned if the index stops being listed on the table or is being deleted while waiting
+func (d *DynamoDB) CreateGSI(ctx context.Context, tableName string, gsi types.GlobalSecondaryIndexUpdate, wait bool, defs ...types.AttributeDefinition) error {
+	if gsi.Create == nil {
+		return errors.New("index update has no Create action")
+	}
+	indexName := aws.ToString(gsi.Create.IndexName)
+
+	table, err := d.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return mapTableErr(err)
+	}
+
+	known := make(map[string]types.AttributeDefinition)
+	for _, def := range table.Table.AttributeDefinitions {
+		known[aws.ToString(def.AttributeName)] = def
+	}
+	for _, def := range defs {
+		known[aws.ToString(def.AttributeName)] = def
+	}
+
+	var attrs []types.AttributeDefinition
+	for _, k := range gsi.Create.KeySchema {
+		def, ok := known[aws.ToString(k.AttributeName)]
+		if !ok {
+			return fmt.Errorf("index %s key attribute %s has no attribute definition", indexName, aws.ToString(k.AttributeName))
+		}
+		attrs = append(attrs, def)
+	}
+
+	input := &dynamodb.UpdateTableInput{
+		TableName:                   aws.String(tableName),
+		AttributeDefinitions:        attrs,
+		GlobalSecondaryIndexUpdates: []types.GlobalSecondaryIndexUpdate{gsi},
+	}
+	if _, err := call(ctx, d, "UpdateTable", input.TableName, input, d.client.UpdateTable); err != nil {
+		return fmt.Errorf("error creating index %s: %w", indexName, mapTableErr(err))
+	}
+
+	if !wait {
+		return nil
+	}
+
+	return d.waitGSI(ctx, tableName, indexName)
+}
+
+// waitGSI polls DescribeTable until the index is ACTIVE and no longer backfilling, an index missing from the table or being deleted will never get there so it fails right away
+func (d *DynamoDB) waitGSI(ctx context.Context, tableName, indexName string) error {
+	for {
+		output, err := d.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("%w: index %s: %w", ErrBackfillTimeout, indexName, ctx.Err())
+			}
+			return mapTableErr(err)
+		}
+
+		idx, ok := findGSI(output.Table, indexName)
+		if !ok || idx.IndexStatus == types.IndexStatusDeleting {
+			return fmt.Errorf("%w: index %s on table %s", ErrIndexNotFound, indexName, tableName)
+		}
+		if idx.IndexStatus == types.IndexStatusActive && !aws.ToBool(idx.Backfilling) {
+			return nil
+		}
+
+		if err := sleep(ctx, gsiPollInterval); err != nil {
+			return fmt.Errorf("%w: index %s: %w", ErrBackfillTimeout, indexName, err)
+		}
+	}
+}
+
+// findGSI returns the description of the global secondary index named indexName
+func findGSI(table *types.TableDescription, indexName string) (types.GlobalSecondaryIndexDescription, bool) {
+	for _, idx := range table.GlobalSecondaryIndexes {
+		if aws.ToString(idx.IndexName) == indexName {
+			return idx, true
+		}
+	}
+	return types.GlobalSecondaryIndexDescription{}, false
+}
@@ -0,0 +1,43 @@
+package ddb_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+	"github.com/jap1998/aws-code-snippets/aws/ddb/ddbtest"
+)
+
+// droppedIndexUpdate accepts every UpdateTable call without creating the index, like a request dynamo rejected after the fact
+type droppedIndexUpdate struct {
+	*ddbtest.Fake
+}
+
+func (droppedIndexUpdate) UpdateTable(ctx context.Context, params *dynamodb.UpdateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTableOutput, error) {
+	return &dynamodb.UpdateTableOutput{}, nil
+}
+
+func TestCreateGSIWaitFailsWhenIndexIsNotListed(t *testing.T) {
+	_, f := newFake(t)
+	d := ddb.NewWithClient(droppedIndexUpdate{f})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := d.CreateGSI(ctx, "t", types.GlobalSecondaryIndexUpdate{Create: &types.CreateGlobalSecondaryIndexAction{
+		IndexName:  aws.String("gsi"),
+		KeySchema:  []types.KeySchemaElement{{AttributeName: aws.String("sk"), KeyType: types.KeyTypeHash}},
+		Projection: &types.Projection{ProjectionType: types.ProjectionTypeKeysOnly},
+	}}, true)
+	if !errors.Is(err, ddb.ErrIndexNotFound) {
+		t.Fatalf("expected ErrIndexNotFound, got %v", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatalf("expected the wait to fail without polling until the deadline")
+	}
+}
@@ -0,0 +1,87 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// PKCount is how many items a partition key value holds
+type PKCount struct {
+	Value types.AttributeValue
+	Count int
+}
+
+// PartitionKeyHistogram counts the items of every partition key value of the table and returns the topN largest partitions, largest first, to spot hot partitions behind throttling.
+// it is a full scan projecting only pkName, so it reads every item of the table once, run it off-peak or use PartitionKeyHistogramSample on large tables. topN <= 0 returns every partition
+func (d *DynamoDB) PartitionKeyHistogram(ctx context.Context, tableName, pkName string, topN int) ([]PKCount, error) {
+	return d.partitionKeyHistogram(ctx, tableName, pkName, topN, 1)
+}
+
+// PartitionKeyHistogramSample is like PartitionKeyHistogram but only scans one parallel scan segment out of 1/fraction, reading about fraction of the table.
+// dynamo splits segments by partition key so the sampled partitions are counted in full but partitions outside the segment are missing, a hot partition may be missed with small fractions
+func (d *DynamoDB) PartitionKeyHistogramSample(ctx context.Context, tableName, pkName string, topN int, fraction float64) ([]PKCount, error) {
+	if fraction <= 0 || fraction > 1 {
+		return nil, fmt.Errorf("fraction %v must be in (0, 1]", fraction)
+	}
+	return d.partitionKeyHistogram(ctx, tableName, pkName, topN, int32(min(math.Round(1/fraction), 1000000)))
+}
+
+// partitionKeyHistogram scans segment 0 of segments projecting pkName and tallies items per partition key value
+func (d *DynamoDB) partitionKeyHistogram(ctx context.Context, tableName, pkName string, topN int, segments int32) ([]PKCount, error) {
+	names, aliases := ExprNames(pkName)
+
+	input := &dynamodb.ScanInput{
+		TableName:                aws.String(tableName),
+		ProjectionExpression:     aws.String(aliases[0]),
+		ExpressionAttributeNames: names,
+	}
+	if segments > 1 {
+		input.Segment = aws.Int32(0)
+		input.TotalSegments = aws.Int32(segments)
+	}
+
+	counts := make(map[string]*PKCount)
+	err := d.ScanPages(ctx, input, func(page []map[string]types.AttributeValue) error {
+		for _, item := range page {
+			v := item[pkName]
+			id := keyString(map[string]types.AttributeValue{pkName: v})
+			if c, ok := counts[id]; ok {
+				c.Count++
+			} else {
+				counts[id] = &PKCount{Value: v, Count: 1}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error scanning partition keys: %w", err)
+	}
+
+	ids := make([]string, 0, len(counts))
+	for id := range counts {
+		ids = append(ids, id)
+	}
+	// ties are broken by key so the result is stable between runs
+	sort.Slice(ids, func(i, j int) bool {
+		if ci, cj := counts[ids[i]].Count, counts[ids[j]].Count; ci != cj {
+			return ci > cj
+		}
+		return ids[i] < ids[j]
+	})
+	if topN > 0 && topN < len(ids) {
+		ids = ids[:topN]
+	}
+
+	histogram := make([]PKCount, len(ids))
+	for i, id := range ids {
+		histogram[i] = *counts[id]
+	}
+
+	return histogram, nil
+}
@@ -0,0 +1,47 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"maps"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// IdempotencyKeyAttribute is the reserved attribute PutItemIdempotent stores the request id of the last write to an item in
+const IdempotencyKeyAttribute = "_idempotency_key"
+
+// PutItemIdempotent is a wrapper around dynamodb.PutItem that applies the write at most once per idKey, meant for at-least-once consumers where idKey is the message or request id.
+// idKey is stored on the item in IdempotencyKeyAttribute and the put is conditioned on it being different, so a redelivery of the same write is detected server-side and reported as applied false with no error.
+// only the id of the latest write is kept per item, an older message redelivered after a newer write to the same item is applied again. any condition already on the input is kept and ErrConditionFailed is returned when it doesn't hold
+func (d *DynamoDB) PutItemIdempotent(ctx context.Context, input *dynamodb.PutItemInput, idKey string) (applied bool, err error) {
+	in := *input
+
+	in.Item = maps.Clone(input.Item)
+	in.Item[IdempotencyKeyAttribute] = &types.AttributeValueMemberS{Value: idKey}
+
+	MergeCondition(&in, "attribute_not_exists(#idem) OR #idem <> :idem",
+		map[string]string{"#idem": IdempotencyKeyAttribute},
+		map[string]types.AttributeValue{":idem": &types.AttributeValueMemberS{Value: idKey}},
+	)
+
+	// the current item tells a duplicate apart from the caller's own condition failing
+	in.ReturnValuesOnConditionCheckFailure = types.ReturnValuesOnConditionCheckFailureAllOld
+
+	_, err = d.PutItem(ctx, &in)
+
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		if current, ok := ccf.Item[IdempotencyKeyAttribute].(*types.AttributeValueMemberS); ok && current.Value == idKey {
+			return false, nil
+		}
+		return false, mapConditionErr(err)
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,157 @@
+package ddb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxImportLineSize is the longest JSONL line ImportJSONL accepts, comfortably above dynamo's 400KB item limit
+const maxImportLineSize = 4 * 1024 * 1024
+
+// ImportJSONL reads one JSON object per line from r and writes each as an item to the table, returning the number of items written.
+// items are flushed in batches of 25 as they are read so the whole dump is never held in memory, blank lines are skipped
+func (d *DynamoDB) ImportJSONL(ctx context.Context, tableName string, r io.Reader) (_ int, err error) {
+	ctx, span := d.startSpan(ctx, "ImportJSONL", aws.String(tableName))
+	defer func() { endSpan(span, err) }()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+
+	bw := d.newBatchWriter(tableName)
+
+	var line int
+	for scanner.Scan() {
+		line++
+
+		b := bytes.TrimSpace(scanner.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+
+		var m map[string]any
+		if err := dec.Decode(&m); err != nil {
+			return bw.written, fmt.Errorf("error parsing line %d: %w", line, err)
+		}
+
+		item, err := FromPlainMap(m)
+		if err != nil {
+			return bw.written, fmt.Errorf("error converting line %d: %w", line, err)
+		}
+
+		if err := bw.add(ctx, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}}); err != nil {
+			return bw.written, fmt.Errorf("error writing items: %w", err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return bw.written, fmt.Errorf("error reading line %d: %w", line+1, err)
+	}
+
+	if err := bw.flush(ctx); err != nil {
+		return bw.written, fmt.Errorf("error writing items: %w", err)
+	}
+
+	return bw.written, nil
+}
+
+// ImportCSV reads a CSV with a header row from r and writes every row as an item to the table, returning the number of items written.
+// cells are converted to the type colTypes declares for their column, strings by default, numbers are checked to parse and binary is decoded from base64 like ExportCSV writes it.
+// blank cells are left out of the item, except for the table key attributes which are looked up with KeyAttributes and must be set on every row. items are flushed in batches as they are read
+func (d *DynamoDB) ImportCSV(ctx context.Context, tableName string, r io.Reader, colTypes map[string]types.ScalarAttributeType) (_ int, err error) {
+	ctx, span := d.startSpan(ctx, "ImportCSV", aws.String(tableName))
+	defer func() { endSpan(span, err) }()
+
+	keyAttrs, err := d.KeyAttributes(ctx, tableName)
+	if err != nil {
+		return 0, err
+	}
+	isKey := make(map[string]bool, len(keyAttrs))
+	for _, k := range keyAttrs {
+		isKey[k] = true
+	}
+
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return 0, fmt.Errorf("error reading header: %w", err)
+	}
+	for _, k := range keyAttrs {
+		if !slices.Contains(header, k) {
+			return 0, fmt.Errorf("header has no column for key attribute %s", k)
+		}
+	}
+
+	bw := d.newBatchWriter(tableName)
+
+	for row := 2; ; row++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return bw.written, fmt.Errorf("error reading row %d: %w", row, err)
+		}
+
+		item := make(map[string]types.AttributeValue, len(header))
+		for i, col := range header {
+			if record[i] == "" {
+				if isKey[col] {
+					return bw.written, fmt.Errorf("row %d has no value for key attribute %s", row, col)
+				}
+				continue
+			}
+
+			av, err := csvValue(record[i], colTypes[col])
+			if err != nil {
+				return bw.written, fmt.Errorf("error converting row %d column %s: %w", row, col, err)
+			}
+			item[col] = av
+		}
+
+		if err := bw.add(ctx, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}}); err != nil {
+			return bw.written, fmt.Errorf("error writing items: %w", err)
+		}
+	}
+
+	if err := bw.flush(ctx); err != nil {
+		return bw.written, fmt.Errorf("error writing items: %w", err)
+	}
+
+	return bw.written, nil
+}
+
+// csvValue converts a CSV cell into an attribute of type t, an empty type is a string
+func csvValue(cell string, t types.ScalarAttributeType) (types.AttributeValue, error) {
+	switch t {
+	case "", types.ScalarAttributeTypeS:
+		return &types.AttributeValueMemberS{Value: cell}, nil
+	case types.ScalarAttributeTypeN:
+		if _, ok := new(big.Float).SetString(cell); !ok {
+			return nil, fmt.Errorf("%q is not a number", cell)
+		}
+		return &types.AttributeValueMemberN{Value: cell}, nil
+	case types.ScalarAttributeTypeB:
+		b, err := base64.StdEncoding.DecodeString(cell)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding base64: %w", err)
+		}
+		return &types.AttributeValueMemberB{Value: b}, nil
+	default:
+		return nil, fmt.Errorf("unsupported attribute type %s", t)
+	}
+}
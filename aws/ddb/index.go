@@ -0,0 +1,188 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// QueryIndexAll is a wrapper around QueryAll that runs the query against the provided index of the table.
+// global secondary indexes are eventually consistent so ConsistentRead set to true returns ErrConsistentReadOnIndex, for consistent reads on a local secondary index set IndexName on the input and call QueryAll directly
+func (d *DynamoDB) QueryIndexAll(ctx context.Context, tableName, indexName string, input *dynamodb.QueryInput) ([]map[string]types.AttributeValue, error) {
+	if aws.ToBool(input.ConsistentRead) {
+		return nil, ErrConsistentReadOnIndex
+	}
+
+	input.TableName = aws.String(tableName)
+	input.IndexName = aws.String(indexName)
+
+	return d.QueryAll(ctx, input)
+}
+
+// QueryIndexByPK returns every item of the index whose partition key pkName equals pkValue
+func (d *DynamoDB) QueryIndexByPK(ctx context.Context, tableName, indexName, pkName string, pkValue any) ([]map[string]types.AttributeValue, error) {
+	return d.queryIndexByKey(ctx, tableName, indexName, expression.Key(pkName).Equal(expression.Value(pkValue)))
+}
+
+// QueryIndexByPrefix returns every item of the index whose partition key pkName equals pkValue and whose sort key skName begins with prefix
+func (d *DynamoDB) QueryIndexByPrefix(ctx context.Context, tableName, indexName, pkName string, pkValue any, skName, prefix string) ([]map[string]types.AttributeValue, error) {
+	keyCond := expression.Key(pkName).Equal(expression.Value(pkValue)).
+		And(expression.Key(skName).BeginsWith(prefix))
+
+	return d.queryIndexByKey(ctx, tableName, indexName, keyCond)
+}
+
+func (d *DynamoDB) queryIndexByKey(ctx context.Context, tableName, indexName string, keyCond expression.KeyConditionBuilder) ([]map[string]types.AttributeValue, error) {
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		return nil, fmt.Errorf("error building key condition: %w", err)
+	}
+
+	return d.QueryIndexAll(ctx, tableName, indexName, &dynamodb.QueryInput{
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})
+}
+
+// QueryIndexProjected is like QueryIndexAll but makes sure the requested attributes are actually projected into the index instead of silently coming back missing.
+// with no attrs Select is set to ALL_PROJECTED_ATTRIBUTES, otherwise every attr is checked against the index projection from DescribeTable and any that isn't projected returns ErrAttributeNotProjected.
+// the projection lookup costs a DescribeTable per call unless WithProjectionCache is used. the input is not modified, the projection aliases are re-aliased when the input already uses them for other names
+func (d *DynamoDB) QueryIndexProjected(ctx context.Context, tableName, indexName string, input *dynamodb.QueryInput, attrs ...string) ([]map[string]types.AttributeValue, error) {
+	in := *input
+
+	if len(attrs) == 0 {
+		in.Select = types.SelectAllProjectedAttributes
+		return d.QueryIndexAll(ctx, tableName, indexName, &in)
+	}
+
+	proj, err := d.indexProjection(ctx, tableName, indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, attr := range attrs {
+		if !proj.includes(attr) {
+			return nil, fmt.Errorf("%w: %s is not projected into index %s", ErrAttributeNotProjected, attr, indexName)
+		}
+	}
+
+	names, aliases := ExprNames(attrs...)
+	merged := make(map[string]string, len(input.ExpressionAttributeNames)+len(names))
+	maps.Copy(merged, input.ExpressionAttributeNames)
+	for i, alias := range aliases {
+		aliases[i] = aliasName(merged, alias, names[alias])
+	}
+
+	in.Select = types.SelectSpecificAttributes
+	in.ProjectionExpression = aws.String(strings.Join(aliases, ", "))
+	in.ExpressionAttributeNames = merged
+
+	return d.QueryIndexAll(ctx, tableName, indexName, &in)
+}
+
+// QueryIndexHydrated queries the index and returns the full base table items of the matches in index order, for indexes that only project keys or a few attributes.
+// the base table key named by baseKeyAttrs is taken from every index item and the items are fetched with BatchGet, an item deleted in between is left out.
+// when the index projects ALL attributes the index items are returned as is without the second fetch, the projection lookup follows WithProjectionCache like QueryIndexProjected
+func (d *DynamoDB) QueryIndexHydrated(ctx context.Context, tableName, indexName string, keyCond expression.KeyConditionBuilder, baseKeyAttrs []string) ([]map[string]types.AttributeValue, error) {
+	proj, err := d.indexProjection(ctx, tableName, indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := d.queryIndexByKey(ctx, tableName, indexName, keyCond)
+	if err != nil {
+		return nil, err
+	}
+	if proj.all || len(items) == 0 {
+		return items, nil
+	}
+
+	keys := make([]map[string]types.AttributeValue, len(items))
+	for i, item := range items {
+		keys[i] = make(map[string]types.AttributeValue, len(baseKeyAttrs))
+		for _, k := range baseKeyAttrs {
+			v, ok := item[k]
+			if !ok {
+				return nil, fmt.Errorf("index %s item %d has no base key attribute %s", indexName, i, k)
+			}
+			keys[i][k] = v
+		}
+	}
+
+	full, err := d.BatchGet(ctx, tableName, keys)
+	if err != nil {
+		return nil, fmt.Errorf("error getting base items: %w", err)
+	}
+
+	hydrated := make([]map[string]types.AttributeValue, 0, len(full))
+	for _, item := range full {
+		if item != nil {
+			hydrated = append(hydrated, item)
+		}
+	}
+
+	return hydrated, nil
+}
+
+// WithProjectionCache lets QueryIndexProjected and QueryIndexHydrated read index projections from the per-table cache shared with KeyAttributes instead of calling DescribeTable on every query.
+// a projection changed after it was cached is picked up once the entry expires, after an hour
+func WithProjectionCache() Option {
+	return func(d *DynamoDB) {
+		d.cacheIndexes = true
+	}
+}
+
+// indexProjection describes which attributes an index carries, attrs always includes the table and index key attributes
+type indexProjection struct {
+	all   bool
+	attrs map[string]bool
+}
+
+func (p *indexProjection) includes(attr string) bool {
+	return p.all || p.attrs[attr]
+}
+
+// indexProjection returns the projection of a global or local secondary index of the table, from the table cache when WithProjectionCache is used
+func (d *DynamoDB) indexProjection(ctx context.Context, tableName, indexName string) (*indexProjection, error) {
+	load := d.describeSchema
+	if d.cacheIndexes {
+		load = d.tableSchema
+	}
+
+	entry, err := load(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, ok := entry.indexes[indexName]
+	if !ok {
+		return nil, fmt.Errorf("table %s has no index %s", tableName, indexName)
+	}
+
+	return &idx.projection, nil
+}
+
+// newIndexProjection returns the attributes carried by an index with keySchema and projection on a table keyed by tableKeys
+func newIndexProjection(tableKeys, keySchema []types.KeySchemaElement, projection *types.Projection) indexProjection {
+	p := indexProjection{
+		all:   projection != nil && projection.ProjectionType == types.ProjectionTypeAll,
+		attrs: make(map[string]bool),
+	}
+	for _, k := range slices.Concat(tableKeys, keySchema) {
+		p.attrs[aws.ToString(k.AttributeName)] = true
+	}
+	if projection != nil {
+		for _, attr := range projection.NonKeyAttributes {
+			p.attrs[attr] = true
+		}
+	}
+	return p
+}
@@ -0,0 +1,127 @@
+package ddb_test
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+	"github.com/jap1998/aws-code-snippets/aws/ddb/ddbtest"
+)
+
+// newIndexedFake returns a Fake with a "pk"/"sk" table named "t" carrying a "gsi" index keyed by "gpk" that also projects "data", and one item in it
+func newIndexedFake(t *testing.T) *ddbtest.Fake {
+	t.Helper()
+
+	f := ddbtest.NewFake()
+	_, err := f.CreateTable(context.Background(), &dynamodb.CreateTableInput{
+		TableName: aws.String("t"),
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("sk"), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{{
+			IndexName:  aws.String("gsi"),
+			KeySchema:  []types.KeySchemaElement{{AttributeName: aws.String("gpk"), KeyType: types.KeyTypeHash}},
+			Projection: &types.Projection{ProjectionType: types.ProjectionTypeInclude, NonKeyAttributes: []string{"data"}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("create table: %v", err)
+	}
+
+	item := key("a", "1")
+	item["gpk"] = &types.AttributeValueMemberS{Value: "g"}
+	item["data"] = &types.AttributeValueMemberS{Value: "x"}
+	if _, err := f.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("t"), Item: item}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	return f
+}
+
+func TestQueryIndexProjectedKeepsCallerAliases(t *testing.T) {
+	d := ddb.NewWithClient(newIndexedFake(t))
+
+	// the caller's key condition uses the same #n0 alias ExprNames hands out for the first projected attribute
+	input := &dynamodb.QueryInput{
+		KeyConditionExpression:    aws.String("#n0 = :g"),
+		ExpressionAttributeNames:  map[string]string{"#n0": "gpk"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":g": &types.AttributeValueMemberS{Value: "g"}},
+	}
+	before := *input
+	beforeNames := maps.Clone(input.ExpressionAttributeNames)
+
+	items, err := d.QueryIndexProjected(context.Background(), "t", "gsi", input, "data", "sk")
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+
+	if len(items) != 1 {
+		t.Fatalf("expected one item, got %v", items)
+	}
+	var got []string
+	for name := range items[0] {
+		got = append(got, name)
+	}
+	slices.Sort(got)
+	if want := []string{"data", "sk"}; !slices.Equal(got, want) {
+		t.Fatalf("expected attributes %v, got %v", want, got)
+	}
+
+	if input.Select != before.Select || input.ProjectionExpression != nil || input.TableName != nil || input.IndexName != nil {
+		t.Fatalf("expected the input to be left untouched, got %+v", input)
+	}
+	if !maps.Equal(input.ExpressionAttributeNames, beforeNames) {
+		t.Fatalf("expected the input names to be left untouched, got %v", input.ExpressionAttributeNames)
+	}
+}
+
+// describeCounter counts the DescribeTable calls reaching the fake
+type describeCounter struct {
+	*ddbtest.Fake
+	calls int
+}
+
+func (c *describeCounter) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	c.calls++
+	return c.Fake.DescribeTable(ctx, params, optFns...)
+}
+
+func TestProjectionCacheSharesTableSchemaCache(t *testing.T) {
+	query := func(d *ddb.DynamoDB) {
+		t.Helper()
+		input := &dynamodb.QueryInput{
+			KeyConditionExpression:    aws.String("#g = :g"),
+			ExpressionAttributeNames:  map[string]string{"#g": "gpk"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":g": &types.AttributeValueMemberS{Value: "g"}},
+		}
+		if _, err := d.QueryIndexProjected(context.Background(), "t", "gsi", input, "data"); err != nil {
+			t.Fatalf("query: %v", err)
+		}
+	}
+
+	counter := &describeCounter{Fake: newIndexedFake(t)}
+	d := ddb.NewWithClient(counter, ddb.WithProjectionCache())
+	if _, err := d.KeyAttributes(context.Background(), "t"); err != nil {
+		t.Fatalf("key attributes: %v", err)
+	}
+	query(d)
+	query(d)
+	if counter.calls != 1 {
+		t.Fatalf("expected the queries to reuse the cached table description, got %d DescribeTable calls", counter.calls)
+	}
+
+	// without the option every query sees the current projection
+	counter.calls = 0
+	d = ddb.NewWithClient(counter)
+	query(d)
+	query(d)
+	if counter.calls != 2 {
+		t.Fatalf("expected a DescribeTable call per query, got %d", counter.calls)
+	}
+}
@@ -0,0 +1,78 @@
+package ddb
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ItemIterator walks the items of a paginated operation one at a time, fetching the next page only when the current one is exhausted
+type ItemIterator struct {
+	fetch    func(ctx context.Context, startKey map[string]types.AttributeValue) ([]map[string]types.AttributeValue, map[string]types.AttributeValue, error)
+	startKey map[string]types.AttributeValue
+	page     []map[string]types.AttributeValue
+	item     map[string]types.AttributeValue
+	done     bool
+	err      error
+}
+
+// NewScanIterator returns an ItemIterator over every item matched by the provided scan. the input is copied so the caller's ExclusiveStartKey is only used as the starting point
+func (d *DynamoDB) NewScanIterator(input *dynamodb.ScanInput) *ItemIterator {
+	in := *input
+
+	return &ItemIterator{
+		startKey: in.ExclusiveStartKey,
+		fetch: func(ctx context.Context, startKey map[string]types.AttributeValue) ([]map[string]types.AttributeValue, map[string]types.AttributeValue, error) {
+			in.ExclusiveStartKey = startKey
+			output, err := call(ctx, d, "Scan", in.TableName, &in, d.reader().Scan)
+			if err != nil {
+				return nil, nil, err
+			}
+			return output.Items, output.LastEvaluatedKey, nil
+		},
+	}
+}
+
+// Next advances the iterator to the next item, fetching a new page if needed. it returns false when there are no more items or an error occurred, check Err to tell them apart
+func (it *ItemIterator) Next(ctx context.Context) bool {
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			it.item = nil
+			return false
+		}
+
+		if err := ctx.Err(); err != nil {
+			it.err = err
+			it.item = nil
+			return false
+		}
+
+		items, lastEvaluatedKey, err := it.fetch(ctx, it.startKey)
+		if err != nil {
+			it.err = err
+			it.item = nil
+			return false
+		}
+
+		it.page = items
+		it.startKey = lastEvaluatedKey
+		if lastEvaluatedKey == nil {
+			it.done = true
+		}
+	}
+
+	it.item, it.page = it.page[0], it.page[1:]
+
+	return true
+}
+
+// Item returns the current item, only valid after a call to Next that returned true
+func (it *ItemIterator) Item() map[string]types.AttributeValue {
+	return it.item
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *ItemIterator) Err() error {
+	return it.err
+}
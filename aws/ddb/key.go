@@ -0,0 +1,55 @@
+package ddb
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Key builds a string key map {pkName: pkValue}, passing skName and skValue after the partition key adds the sort key as well.
+// it panics when sk isn't empty or a name value pair, a wrong argument count is a programming error
+func Key(pkName, pkValue string, sk ...string) map[string]types.AttributeValue {
+	key := map[string]types.AttributeValue{pkName: &types.AttributeValueMemberS{Value: pkValue}}
+
+	switch len(sk) {
+	case 0:
+	case 2:
+		key[sk[0]] = &types.AttributeValueMemberS{Value: sk[1]}
+	default:
+		panic("ddb.Key: sort key must be passed as a name and a value")
+	}
+
+	return key
+}
+
+// KeyN builds a numeric partition key map {pkName: pkValue}
+func KeyN(pkName string, pkValue int64) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{pkName: numberValue(pkValue)}
+}
+
+// KeyNN builds a key map with a numeric partition key and a numeric sort key
+func KeyNN(pkName string, pkValue int64, skName string, skValue int64) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		pkName: numberValue(pkValue),
+		skName: numberValue(skValue),
+	}
+}
+
+// KeySN builds a key map with a string partition key and a numeric sort key, e.g. an id and a timestamp
+func KeySN(pkName, pkValue string, skName string, skValue int64) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		pkName: &types.AttributeValueMemberS{Value: pkValue},
+		skName: numberValue(skValue),
+	}
+}
+
+// KeyB builds a binary partition key map {pkName: pkValue}, pkValue is sent as is and must not be modified while the key is in use
+func KeyB(pkName string, pkValue []byte) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{pkName: &types.AttributeValueMemberB{Value: pkValue}}
+}
+
+// KeyBS builds a key map with a binary partition key and a string sort key, e.g. a hashed id and a record type
+func KeyBS(pkName string, pkValue []byte, skName, skValue string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		pkName: &types.AttributeValueMemberB{Value: pkValue},
+		skName: &types.AttributeValueMemberS{Value: skValue},
+	}
+}
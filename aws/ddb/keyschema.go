@@ -0,0 +1,139 @@
+package ddb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// keySchemaTTL is how long a table's key attributes, billing mode and indexes are cached before DescribeTable is called again
+const keySchemaTTL = time.Hour
+
+// keySchemaCache holds the key attributes, their types, the billing mode and the secondary indexes of every table looked up by keySchema, BillingMode, ValidateItemKeys or the index helpers
+type keySchemaCache struct {
+	mu      sync.Mutex
+	entries map[string]keySchemaEntry
+}
+
+type keySchemaEntry struct {
+	attrs       []string
+	keyTypes    map[string]types.ScalarAttributeType
+	billingMode types.BillingMode
+	indexes     map[string]tableIndex
+	expires     time.Time
+}
+
+// tableIndex is a global or local secondary index of a cached table
+type tableIndex struct {
+	global     bool
+	projection indexProjection
+}
+
+// KeyAttributes returns the key attribute names of the table, the partition key first followed by the sort key if the table has one.
+// the result of DescribeTable is cached per table for an hour so calling it before every request is cheap
+func (d *DynamoDB) KeyAttributes(ctx context.Context, tableName string) ([]string, error) {
+	attrs, err := d.keySchema(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string(nil), attrs...), nil
+}
+
+// BillingMode returns whether the table is PROVISIONED or PAY_PER_REQUEST, a table that never had its billing mode set reports no summary and is provisioned.
+// it is cached for an hour along with the key attributes, so a mode switched in the meantime is only seen once the cache expires
+func (d *DynamoDB) BillingMode(ctx context.Context, tableName string) (types.BillingMode, error) {
+	entry, err := d.tableSchema(ctx, tableName)
+	if err != nil {
+		return "", err
+	}
+	return entry.billingMode, nil
+}
+
+// keySchema returns the cached key attributes of the table. the returned slice is shared and must not be modified
+func (d *DynamoDB) keySchema(ctx context.Context, tableName string) ([]string, error) {
+	entry, err := d.tableSchema(ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+	return entry.attrs, nil
+}
+
+// tableSchema returns the cached key attributes, key types, billing mode and indexes of the table, calling DescribeTable when they are missing or expired
+func (d *DynamoDB) tableSchema(ctx context.Context, tableName string) (keySchemaEntry, error) {
+	d.keySchemas.mu.Lock()
+	entry, ok := d.keySchemas.entries[tableName]
+	d.keySchemas.mu.Unlock()
+
+	if ok && time.Now().Before(entry.expires) {
+		return entry, nil
+	}
+
+	return d.describeSchema(ctx, tableName)
+}
+
+// describeSchema calls DescribeTable and replaces the cached entry of the table with its result
+func (d *DynamoDB) describeSchema(ctx context.Context, tableName string) (keySchemaEntry, error) {
+	output, err := d.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return keySchemaEntry{}, mapTableErr(err)
+	}
+
+	var hash, sort string
+	for _, k := range output.Table.KeySchema {
+		switch k.KeyType {
+		case types.KeyTypeHash:
+			hash = aws.ToString(k.AttributeName)
+		case types.KeyTypeRange:
+			sort = aws.ToString(k.AttributeName)
+		}
+	}
+
+	attrs := []string{hash}
+	if sort != "" {
+		attrs = append(attrs, sort)
+	}
+
+	keyTypes := make(map[string]types.ScalarAttributeType, len(attrs))
+	for _, a := range output.Table.AttributeDefinitions {
+		if name := aws.ToString(a.AttributeName); name == hash || name == sort {
+			keyTypes[name] = a.AttributeType
+		}
+	}
+
+	indexes := make(map[string]tableIndex)
+	for _, idx := range output.Table.GlobalSecondaryIndexes {
+		indexes[aws.ToString(idx.IndexName)] = tableIndex{global: true, projection: newIndexProjection(output.Table.KeySchema, idx.KeySchema, idx.Projection)}
+	}
+	for _, idx := range output.Table.LocalSecondaryIndexes {
+		indexes[aws.ToString(idx.IndexName)] = tableIndex{projection: newIndexProjection(output.Table.KeySchema, idx.KeySchema, idx.Projection)}
+	}
+
+	entry := keySchemaEntry{
+		attrs:       attrs,
+		keyTypes:    keyTypes,
+		billingMode: billingMode(output.Table),
+		indexes:     indexes,
+		expires:     time.Now().Add(keySchemaTTL),
+	}
+
+	d.keySchemas.mu.Lock()
+	if d.keySchemas.entries == nil {
+		d.keySchemas.entries = make(map[string]keySchemaEntry)
+	}
+	d.keySchemas.entries[tableName] = entry
+	d.keySchemas.mu.Unlock()
+
+	return entry, nil
+}
+
+// billingMode returns the billing mode of the table, PROVISIONED when the description carries no summary
+func billingMode(table *types.TableDescription) types.BillingMode {
+	if s := table.BillingModeSummary; s != nil && s.BillingMode != "" {
+		return s.BillingMode
+	}
+	return types.BillingModeProvisioned
+}
@@ -0,0 +1,62 @@
+package ddb
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// WithLogger logs every dynamo call made through the wrapper, successful calls at debug level and failed ones at error level.
+// paginating helpers log once per page so paging is visible, without this option nothing is logged
+func WithLogger(logger *slog.Logger) Option {
+	return func(d *DynamoDB) {
+		d.logger = logger
+	}
+}
+
+// logCall logs a finished dynamo call with its table, operation, item count and duration
+func (d *DynamoDB) logCall(ctx context.Context, operation string, tableName *string, input, output any, duration time.Duration, err error) {
+	attrs := []slog.Attr{
+		slog.String("operation", operation),
+		slog.String("table", aws.ToString(tableName)),
+		slog.Duration("duration", duration),
+	}
+
+	if err != nil {
+		d.logger.LogAttrs(ctx, slog.LevelError, "dynamodb call failed", append(attrs, slog.Any("error", err))...)
+		return
+	}
+
+	d.logger.LogAttrs(ctx, slog.LevelDebug, "dynamodb call", append(attrs, slog.Int("items", itemCount(input, output)))...)
+}
+
+// itemCount returns how many items an operation returned or wrote
+func itemCount(input, output any) int {
+	switch o := output.(type) {
+	case *dynamodb.GetItemOutput:
+		if o.Item == nil {
+			return 0
+		}
+		return 1
+	case *dynamodb.QueryOutput:
+		return len(o.Items)
+	case *dynamodb.ScanOutput:
+		return len(o.Items)
+	case *dynamodb.BatchWriteItemOutput:
+		var written int
+		if in, ok := input.(*dynamodb.BatchWriteItemInput); ok {
+			for _, writes := range in.RequestItems {
+				written += len(writes)
+			}
+		}
+		for _, writes := range o.UnprocessedItems {
+			written -= len(writes)
+		}
+		return written
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,46 @@
+package ddb
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Metrics holds counters the wrapper keeps about its calls, cheap enough to always be on and meant to be polled into a dashboard without an OTel setup
+type Metrics struct {
+	// Throttles counts calls dynamo rejected for exceeding capacity or request limits after the SDK gave up retrying them
+	Throttles atomic.Int64
+	// Retries counts the batch calls made again for the items dynamo left unprocessed, retries done inside the SDK are not included
+	Retries atomic.Int64
+	// ConditionFailures counts calls rejected because their condition expression didn't hold
+	ConditionFailures atomic.Int64
+}
+
+// Metrics returns the live counters of the wrapper, they keep counting after the call so read them with Load when needed
+func (d *DynamoDB) Metrics() *Metrics {
+	return &d.metrics
+}
+
+// Reset sets every counter back to zero, e.g. between tests sharing a wrapper
+func (m *Metrics) Reset() {
+	m.Throttles.Store(0)
+	m.Retries.Store(0)
+	m.ConditionFailures.Store(0)
+}
+
+// observe counts the outcome of a single call
+func (m *Metrics) observe(err error) {
+	if err == nil {
+		return
+	}
+
+	if isThrottle(err) {
+		m.Throttles.Add(1)
+	}
+
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		m.ConditionFailures.Add(1)
+	}
+}
@@ -0,0 +1,58 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// QueryMultiPK queries every partition in pkValues concurrently, each one read to the end, and returns the items keyed by partition key value, a partition with no items maps to an empty slice.
+// each query starts with KeyConditionExpression "#pk = :pk" and extra, when not nil, can refine it before it is sent, e.g. by appending " AND begins_with(#sk, :sk)" and adding #sk and :sk to the already allocated maps.
+// workers queries run at a time, workers <= 0 uses the wrapper's WithMaxConcurrency, 4 by default, capped at 16, and the first error cancels the others
+func (d *DynamoDB) QueryMultiPK(ctx context.Context, tableName, pkName string, pkValues []string, workers int, extra func(*dynamodb.QueryInput)) (_ map[string][]map[string]types.AttributeValue, err error) {
+	ctx, span := d.startSpan(ctx, "QueryMultiPK", aws.String(tableName))
+	defer func() { endSpan(span, err) }()
+
+	pks := make([]string, 0, len(pkValues))
+	seen := make(map[string]bool, len(pkValues))
+	for _, pk := range pkValues {
+		if !seen[pk] {
+			seen[pk] = true
+			pks = append(pks, pk)
+		}
+	}
+
+	var mu sync.Mutex
+	results := make(map[string][]map[string]types.AttributeValue, len(pks))
+
+	err = forEachConcurrent(ctx, d.workers(workers), len(pks), func(ctx context.Context, i int) error {
+		input := &dynamodb.QueryInput{
+			TableName:                 aws.String(tableName),
+			KeyConditionExpression:    aws.String("#pk = :pk"),
+			ExpressionAttributeNames:  map[string]string{"#pk": pkName},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":pk": &types.AttributeValueMemberS{Value: pks[i]}},
+		}
+		if extra != nil {
+			extra(input)
+		}
+
+		items, err := d.QueryAll(ctx, input)
+		if err != nil {
+			return fmt.Errorf("error querying partition %s: %w", pks[i], err)
+		}
+
+		mu.Lock()
+		results[pks[i]] = items
+		mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,94 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GetNested is like GetOneProjected but each path is a document path into the item, e.g. "address.city" or "tags[0]" or "orders[2].items[0].sku".
+// every path segment is aliased so reserved words are safe anywhere in the path, the returned item keeps the nesting of the projected attributes
+func (d *DynamoDB) GetNested(ctx context.Context, tableName string, key map[string]types.AttributeValue, paths ...string) (map[string]types.AttributeValue, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(tableName),
+		Key:       key,
+	}
+
+	if len(paths) > 0 {
+		projection, names, err := documentPaths(paths)
+		if err != nil {
+			return nil, err
+		}
+		input.ProjectionExpression = aws.String(projection)
+		input.ExpressionAttributeNames = names
+	}
+
+	return d.GetOne(ctx, input)
+}
+
+// documentPaths builds a projection expression for the provided document paths, aliasing every attribute name segment once no matter how many paths use it
+func documentPaths(paths []string) (string, map[string]string, error) {
+	names := make(map[string]string)
+	aliasOf := make(map[string]string)
+
+	exprs := make([]string, len(paths))
+	for i, p := range paths {
+		var sb strings.Builder
+		segments := strings.Split(p, ".")
+		for j, seg := range segments {
+			name, indexes, err := splitSegment(seg)
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid path %q: %w", p, err)
+			}
+
+			alias, ok := aliasOf[name]
+			if !ok {
+				alias = fmt.Sprintf("#d%d", len(aliasOf))
+				aliasOf[name] = alias
+				names[alias] = name
+			}
+
+			if j > 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(alias)
+			for _, idx := range indexes {
+				sb.WriteString("[" + strconv.Itoa(idx) + "]")
+			}
+		}
+		exprs[i] = sb.String()
+	}
+
+	return strings.Join(exprs, ", "), names, nil
+}
+
+// splitSegment splits a path segment like "tags[0][1]" into its attribute name and list indexes
+func splitSegment(seg string) (string, []int, error) {
+	name, rest, hasIndex := strings.Cut(seg, "[")
+	if name == "" || strings.Contains(name, "]") {
+		return "", nil, fmt.Errorf("invalid attribute name in segment %q", seg)
+	}
+	if !hasIndex {
+		return name, nil, nil
+	}
+
+	var indexes []int
+	for _, part := range strings.Split(rest, "[") {
+		digits, ok := strings.CutSuffix(part, "]")
+		if !ok {
+			return "", nil, fmt.Errorf("unterminated list index in segment %q", seg)
+		}
+		idx, err := strconv.Atoi(digits)
+		if err != nil || idx < 0 {
+			return "", nil, fmt.Errorf("invalid list index %q in segment %q", digits, seg)
+		}
+		indexes = append(indexes, idx)
+	}
+
+	return name, indexes, nil
+}
@@ -0,0 +1,86 @@
+package ddb
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/jap1998/aws-code-snippets/aws/configuration"
+)
+
+// Option configures optional behavior of the DynamoDB wrapper
+type Option func(*DynamoDB)
+
+// MustNew inits a new client from the default config and applies the provided options, if an error occurs it panics.
+func MustNew(ctx context.Context, opts ...Option) *DynamoDB {
+	d := &DynamoDB{}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	c := configuration.MustGetConfig(ctx)
+	d.client = dynamodb.NewFromConfig(c, d.clientOptions()...)
+	if d.readRegion != "" {
+		d.readClient = dynamodb.NewFromConfig(c, d.clientOptions(d.readRegionOption)...)
+	}
+
+	return d
+}
+
+// NewWithClient wraps an already initialized client, or any other DynamoAPI implementation like ddbtest.Fake, and applies the provided options.
+// a *dynamodb.Client is rebuilt from its own options with the WithClientOptions functions applied on top and its retryer hooked up to WithBudget, other implementations ignore them
+func NewWithClient(client DynamoAPI, opts ...Option) *DynamoDB {
+	d := &DynamoDB{}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	if c, ok := client.(*dynamodb.Client); ok {
+		client = dynamodb.New(c.Options(), d.clientOptions()...)
+		if d.readRegion != "" {
+			d.readClient = dynamodb.New(c.Options(), d.clientOptions(d.readRegionOption)...)
+		}
+	}
+	d.client = client
+
+	return d
+}
+
+// WithClientOptions passes the provided option functions to the underlying dynamodb client when it is constructed
+func WithClientOptions(optFns ...func(*dynamodb.Options)) Option {
+	return func(d *DynamoDB) {
+		d.clientOptFns = append(d.clientOptFns, optFns...)
+	}
+}
+
+// clientOptions returns the option functions a client is built with, extra is applied after WithClientOptions and the retry budget hook always comes last so it wraps the final retryer
+func (d *DynamoDB) clientOptions(extra ...func(*dynamodb.Options)) []func(*dynamodb.Options) {
+	return slices.Concat(d.clientOptFns, extra, []func(*dynamodb.Options){retryBudgetOption})
+}
+
+// WithDefaultTimeout bounds every dynamo call made through the wrapper to d when the caller's context has no deadline.
+// a deadline already on the context always takes precedence and is never shortened
+func WithDefaultTimeout(d time.Duration) Option {
+	return func(db *DynamoDB) {
+		db.defaultTimeout = d
+	}
+}
+
+// WithRetryer replaces the SDK's default retryer on the underlying client with the one returned by r, e.g. retry.NewStandard with custom max attempts or backoff.
+// it governs retries of each individual dynamo call, the package's own retries of unprocessed batch items run on top of it so keep both bounded to avoid multiplying attempts
+func WithRetryer(r func() aws.Retryer) Option {
+	return WithClientOptions(func(o *dynamodb.Options) {
+		o.Retryer = r()
+	})
+}
+
+// WithAPIOptions appends the provided functions to the APIOptions of the underlying client, the escape hatch to add SDK middleware the wrapper doesn't expose, e.g. a correlation id header on every request.
+// the middleware runs inside the SDK's own stack for every attempt of every call, after the wrapper's tracing, logging and circuit breaker have already run, and only applies to a *dynamodb.Client
+func WithAPIOptions(opts ...func(*middleware.Stack) error) Option {
+	return WithClientOptions(func(o *dynamodb.Options) {
+		o.APIOptions = append(o.APIOptions, opts...)
+	})
+}
@@ -0,0 +1,82 @@
+package ddb
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// QueryPages is a wrapper around dynamodb.Query that calls fn with the items of every page as it is fetched instead of collecting them all.
+// paging stops as soon as fn returns an error, which is returned as is
+func (d *DynamoDB) QueryPages(ctx context.Context, input *dynamodb.QueryInput, fn func(page []map[string]types.AttributeValue) error) (err error) {
+	ctx, span := d.startSpan(ctx, "QueryPages", input.TableName)
+	defer func() { endSpan(span, err) }()
+
+	var lastEvaluatedKey map[string]types.AttributeValue
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		input.ExclusiveStartKey = lastEvaluatedKey
+		output, err := call(ctx, d, "Query", input.TableName, input, d.reader().Query)
+		if err != nil {
+			return err
+		}
+		if err := fn(output.Items); err != nil {
+			return err
+		}
+		if output.LastEvaluatedKey == nil {
+			break
+		}
+		lastEvaluatedKey = output.LastEvaluatedKey
+	}
+
+	return nil
+}
+
+// ScanPages is a wrapper around dynamodb.Scan that calls fn with the items of every page as it is fetched instead of collecting them all.
+// paging stops as soon as fn returns an error, which is returned as is
+func (d *DynamoDB) ScanPages(ctx context.Context, input *dynamodb.ScanInput, fn func(page []map[string]types.AttributeValue) error) (err error) {
+	ctx, span := d.startSpan(ctx, "ScanPages", input.TableName)
+	defer func() { endSpan(span, err) }()
+
+	var lastEvaluatedKey map[string]types.AttributeValue
+	var scanned int
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		input.ExclusiveStartKey = lastEvaluatedKey
+		output, err := call(ctx, d, "Scan", input.TableName, input, d.reader().Scan)
+		if err != nil {
+			return err
+		}
+		if err := fn(output.Items); err != nil {
+			return err
+		}
+		if output.LastEvaluatedKey == nil {
+			break
+		}
+		scanned += int(output.ScannedCount)
+		if err := d.checkScanBudget(scanned); err != nil {
+			return err
+		}
+		lastEvaluatedKey = output.LastEvaluatedKey
+	}
+
+	return nil
+}
+
+// ScanPage is a wrapper around dynamodb.Scan that fetches a single page starting at input.ExclusiveStartKey and returns its items along with the key to resume from.
+// lastKey is nil once the scan is complete, persisting it and passing it back as ExclusiveStartKey lets a scan continue across invocations, a nil start key scans from the beginning
+func (d *DynamoDB) ScanPage(ctx context.Context, input *dynamodb.ScanInput) (items []map[string]types.AttributeValue, lastKey map[string]types.AttributeValue, err error) {
+	output, err := call(ctx, d, "Scan", input.TableName, input, d.reader().Scan)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return output.Items, output.LastEvaluatedKey, nil
+}
@@ -5,10 +5,15 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
+// PaginationOps selects a window of the matching items, the items from position Skip up to but not including Skip + Limit.
+// Limit is the page size, so Skip = n * Limit is the zero based page n, the same window for QueryWithPagination and QueryWithFilterPaginated
 type PaginationOps struct {
 	dynamodb.QueryInput
 	Skip  int
 	Limit int
+	// SkipCount skips the Select COUNT query QueryWithPagination runs to compute Count, halving its read cost when no total is needed.
+	// Count is then -1 and TotalPages 0
+	SkipCount bool
 }
 
 type PaginatedResults struct {
@@ -16,4 +21,29 @@ type PaginatedResults struct {
 	Skip  int
 	Limit int
 	Count int
+	// TotalPages is ceil(Count / Limit), 0 when Limit is 0
+	TotalPages int
+	// Page is the zero based page the results belong to, Skip / Limit
+	Page int
+}
+
+// PaginatedResultsOf is PaginatedResults with the items unmarshaled into T
+type PaginatedResultsOf[T any] struct {
+	Items      []T
+	Skip       int
+	Limit      int
+	Count      int
+	TotalPages int
+	Page       int
+}
+
+// pageNumbers returns the total number of pages and the zero based current page for a skip/limit window over count items, an unknown count of -1 gives 0 total pages
+func pageNumbers(skip, limit, count int) (totalPages, page int) {
+	if limit <= 0 {
+		return 0, 0
+	}
+	if count < 0 {
+		return 0, skip / limit
+	}
+	return (count + limit - 1) / limit, skip / limit
 }
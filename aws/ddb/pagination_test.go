@@ -0,0 +1,61 @@
+package ddb_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+)
+
+func TestPaginationHelpersAgreeOnWindow(t *testing.T) {
+	d, f := newFake(t)
+	f.PageSize = 3
+	var sks []string
+	for i := 0; i < 10; i++ {
+		sks = append(sks, fmt.Sprintf("%03d", i))
+	}
+	putItems(t, f, "a", sks...)
+
+	keyCond := expression.Key("pk").Equal(expression.Value("a"))
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCond).Build()
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+
+	for _, skip := range []int{0, 4, 8} {
+		ops := ddb.PaginationOps{
+			QueryInput: dynamodb.QueryInput{
+				TableName:                 aws.String("t"),
+				KeyConditionExpression:    expr.KeyCondition(),
+				ExpressionAttributeNames:  expr.Names(),
+				ExpressionAttributeValues: expr.Values(),
+			},
+			Skip:  skip,
+			Limit: 4,
+		}
+
+		plain, err := ddb.QueryWithPaginationAs[row](context.Background(), d, &ops)
+		if err != nil {
+			t.Fatalf("skip %d: %v", skip, err)
+		}
+
+		// a filter every item passes must not change the window
+		filtered, err := ddb.QueryWithFilterPaginated[row](context.Background(), d, "t", keyCond, expression.AttributeExists(expression.Name("pk")), ops)
+		if err != nil {
+			t.Fatalf("skip %d filtered: %v", skip, err)
+		}
+
+		want := sks[skip:min(skip+4, len(sks))]
+		if !slices.Equal(sortKeys(plain.Items), want) || !slices.Equal(sortKeys(filtered.Items), want) {
+			t.Fatalf("skip %d: expected %v from both helpers, got %v and %v", skip, want, sortKeys(plain.Items), sortKeys(filtered.Items))
+		}
+		if plain.Page != filtered.Page || plain.TotalPages != filtered.TotalPages || plain.Count != filtered.Count {
+			t.Fatalf("skip %d: expected the same page numbers, got %+v and %+v", skip, plain, filtered)
+		}
+	}
+}
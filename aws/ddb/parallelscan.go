@@ -0,0 +1,148 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ScanCheckpoint records how far every segment of a parallel scan got, it marshals to json so a long running job can persist it and resume with ParallelScanResumable after a crash
+type ScanCheckpoint struct {
+	// Segments holds the progress of each segment, its length is the number of segments the scan is split into
+	Segments []SegmentCheckpoint `json:"segments"`
+}
+
+// SegmentCheckpoint is the progress of a single segment of a parallel scan
+type SegmentCheckpoint struct {
+	// Token encodes the LastEvaluatedKey of the last page handled, empty when the segment hasn't started
+	Token string `json:"token,omitempty"`
+	// Done is set once the last page of the segment has been handled
+	Done bool `json:"done,omitempty"`
+}
+
+// NewScanCheckpoint returns the checkpoint of a parallel scan split into totalSegments segments that hasn't started yet
+func NewScanCheckpoint(totalSegments int) *ScanCheckpoint {
+	return &ScanCheckpoint{Segments: make([]SegmentCheckpoint, max(totalSegments, 1))}
+}
+
+// Done reports whether every segment of the scan is complete
+func (c *ScanCheckpoint) Done() bool {
+	for _, s := range c.Segments {
+		if !s.Done {
+			return false
+		}
+	}
+	return true
+}
+
+// ParallelScanResumable scans the table split into len(checkpoint.Segments) segments, scanning workers segments at a time, and calling fn with every page of every segment.
+// each segment resumes after the position recorded in checkpoint and segments already done are skipped. checkpoint is advanced in place once fn returns nil for a page, and save, when not nil,
+// is called with a copy after every advance so the job can persist it, calls to fn may run concurrently but calls to save never do. the first error stops every segment and is returned,
+// checkpoint then holds the progress made so far. tokens are encoded like pagination tokens, so they are encrypted when WithTokenKey is used.
+// workers <= 0 uses the wrapper's WithMaxConcurrency, 4 by default, and anything above 16 is capped
+func (d *DynamoDB) ParallelScanResumable(ctx context.Context, input *dynamodb.ScanInput, checkpoint *ScanCheckpoint, workers int, fn func(segment int, page []map[string]types.AttributeValue) error, save func(checkpoint ScanCheckpoint) error) (err error) {
+	ctx, span := d.startSpan(ctx, "ParallelScanResumable", input.TableName)
+	defer func() { endSpan(span, err) }()
+
+	if len(checkpoint.Segments) == 0 {
+		return errors.New("checkpoint has no segments")
+	}
+
+	type pending struct {
+		segment int
+		token   string
+	}
+
+	var todo []pending
+	for segment, sc := range checkpoint.Segments {
+		if !sc.Done {
+			todo = append(todo, pending{segment: segment, token: sc.Token})
+		}
+	}
+
+	var mu sync.Mutex
+	total := int32(len(checkpoint.Segments))
+
+	return forEachConcurrent(ctx, d.workers(workers), len(todo), func(ctx context.Context, i int) error {
+		p := todo[i]
+		err := d.scanSegment(ctx, input, p.segment, total, p.token, fn, func(sc SegmentCheckpoint) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			checkpoint.Segments[p.segment] = sc
+			if save == nil {
+				return nil
+			}
+			if err := save(ScanCheckpoint{Segments: slices.Clone(checkpoint.Segments)}); err != nil {
+				return fmt.Errorf("error saving checkpoint: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error scanning segment %d: %w", p.segment, err)
+		}
+		return nil
+	})
+}
+
+// scanSegment pages a single segment of a parallel scan starting after token, calling advance with the segment progress after fn handled each page
+func (d *DynamoDB) scanSegment(ctx context.Context, input *dynamodb.ScanInput, segment int, total int32, token string, fn func(int, []map[string]types.AttributeValue) error, advance func(SegmentCheckpoint) error) error {
+	in := *input
+	in.Segment = aws.Int32(int32(segment))
+	in.TotalSegments = aws.Int32(total)
+
+	cur, err := d.decodeToken(token)
+	if err != nil {
+		return err
+	}
+	if cur != nil {
+		in.ExclusiveStartKey = cur.key()
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, lastKey, err := d.ScanPage(ctx, &in)
+		if err != nil {
+			return err
+		}
+		if err := fn(segment, items); err != nil {
+			return err
+		}
+
+		next := SegmentCheckpoint{Done: lastKey == nil}
+		if next.Token, err = d.encodeToken(lastKey, false); err != nil {
+			return err
+		}
+		if err := advance(next); err != nil {
+			return err
+		}
+
+		if lastKey == nil {
+			return nil
+		}
+		in.ExclusiveStartKey = lastKey
+	}
+}
+
+// ParallelScanEach scans the table split into segments parallel segments and calls fn with every item, holding no more than a page per segment in memory.
+// fn is called from several goroutines at once and must be safe for concurrent use, the first error it returns cancels every segment and is returned.
+// segments run workers at a time like ParallelScanResumable, which it is built on
+func (d *DynamoDB) ParallelScanEach(ctx context.Context, input *dynamodb.ScanInput, segments, workers int, fn func(item map[string]types.AttributeValue) error) error {
+	return d.ParallelScanResumable(ctx, input, NewScanCheckpoint(segments), workers, func(_ int, page []map[string]types.AttributeValue) error {
+		for _, item := range page {
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil)
+}
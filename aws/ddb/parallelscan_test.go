@@ -0,0 +1,153 @@
+package ddb_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/jap1998/aws-code-snippets/aws/ddb"
+)
+
+// seen counts how many times every item of the "t" table was handled, safe for concurrent use
+type seen struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (s *seen) add(items ...map[string]types.AttributeValue) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+	}
+	for _, item := range items {
+		pk := item["pk"].(*types.AttributeValueMemberS).Value
+		sk := item["sk"].(*types.AttributeValueMemberS).Value
+		s.counts[pk+"/"+sk]++
+	}
+}
+
+// check fails unless every one of the n items written by putPartitions was handled exactly once
+func (s *seen) check(t *testing.T, n int) {
+	t.Helper()
+
+	if len(s.counts) != n {
+		t.Fatalf("expected %d items, got %d", n, len(s.counts))
+	}
+	for id, c := range s.counts {
+		if c != 1 {
+			t.Fatalf("expected %s once, got it %d times", id, c)
+		}
+	}
+}
+
+// putPartitions writes n items spread over n/2 partitions so they land in different segments
+func putPartitions(t *testing.T, d *ddb.DynamoDB, n int) {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		item := key(fmt.Sprintf("p%d", i/2), fmt.Sprint(i%2))
+		if _, err := d.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("t"), Item: item}); err != nil {
+			t.Fatalf("put: %v", err)
+		}
+	}
+}
+
+func TestParallelScanResumableRestartsFromCheckpoint(t *testing.T) {
+	d, f := newFake(t)
+	f.PageSize = 3
+	putPartitions(t, d, 100)
+
+	input := &dynamodb.ScanInput{TableName: aws.String("t")}
+	crash := errors.New("crash")
+
+	var handled seen
+	var saved []byte
+	var pages int
+	var mu sync.Mutex
+	save := func(c ddb.ScanCheckpoint) (err error) {
+		saved, err = json.Marshal(c)
+		return err
+	}
+
+	// the job dies on its sixth page, that page is not handled and not checkpointed
+	err := d.ParallelScanResumable(context.Background(), input, ddb.NewScanCheckpoint(4), 0, func(_ int, page []map[string]types.AttributeValue) error {
+		mu.Lock()
+		pages++
+		n := pages
+		mu.Unlock()
+		if n == 6 {
+			return crash
+		}
+		handled.add(page...)
+		return nil
+	}, save)
+	if !errors.Is(err, crash) {
+		t.Fatalf("expected the crash, got %v", err)
+	}
+
+	var checkpoint ddb.ScanCheckpoint
+	if err := json.Unmarshal(saved, &checkpoint); err != nil {
+		t.Fatalf("unmarshal checkpoint: %v", err)
+	}
+	if checkpoint.Done() {
+		t.Fatal("expected the checkpoint to be incomplete after the crash")
+	}
+
+	// a new process resumes from what was saved
+	resumed := ddb.NewWithClient(f)
+	err = resumed.ParallelScanResumable(context.Background(), input, &checkpoint, 0, func(_ int, page []map[string]types.AttributeValue) error {
+		handled.add(page...)
+		return nil
+	}, save)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+
+	if !checkpoint.Done() {
+		t.Fatal("expected the checkpoint to be done after the resumed scan")
+	}
+	handled.check(t, 100)
+}
+
+func TestParallelScanEachDeliversEveryItemOnce(t *testing.T) {
+	for _, segments := range []int{2, 7} {
+		t.Run(fmt.Sprint(segments), func(t *testing.T) {
+			d, f := newFake(t, ddb.WithMaxConcurrency(3))
+			f.PageSize = 4
+			putPartitions(t, d, 101)
+
+			var handled seen
+			err := d.ParallelScanEach(context.Background(), &dynamodb.ScanInput{TableName: aws.String("t")}, segments, 0, func(item map[string]types.AttributeValue) error {
+				handled.add(item)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+
+			handled.check(t, 101)
+		})
+	}
+}
+
+func TestParallelScanEachStopsOnError(t *testing.T) {
+	d, f := newFake(t)
+	f.PageSize = 2
+	putPartitions(t, d, 50)
+
+	stop := errors.New("stop")
+	err := d.ParallelScanEach(context.Background(), &dynamodb.ScanInput{TableName: aws.String("t")}, 4, 2, func(item map[string]types.AttributeValue) error {
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("expected the error from fn, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// EnablePITR turns on point in time recovery for the table, enabling it when it is already on is a no-op
+func (d *DynamoDB) EnablePITR(ctx context.Context, tableName string) error {
+	return d.setPITR(ctx, tableName, true)
+}
+
+// DisablePITR turns off point in time recovery for the table, disabling it when it is already off is a no-op
+func (d *DynamoDB) DisablePITR(ctx context.Context, tableName string) error {
+	return d.setPITR(ctx, tableName, false)
+}
+
+// DescribePITR returns whether point in time recovery is ENABLED or DISABLED for the table
+func (d *DynamoDB) DescribePITR(ctx context.Context, tableName string) (types.PointInTimeRecoveryStatus, error) {
+	input := &dynamodb.DescribeContinuousBackupsInput{
+		TableName: aws.String(tableName),
+	}
+
+	output, err := call(ctx, d, "DescribeContinuousBackups", input.TableName, input, d.client.DescribeContinuousBackups)
+	if err != nil {
+		return "", mapTableErr(err)
+	}
+
+	desc := output.ContinuousBackupsDescription
+	if desc == nil || desc.PointInTimeRecoveryDescription == nil {
+		return types.PointInTimeRecoveryStatusDisabled, nil
+	}
+
+	return desc.PointInTimeRecoveryDescription.PointInTimeRecoveryStatus, nil
+}
+
+func (d *DynamoDB) setPITR(ctx context.Context, tableName string, enabled bool) error {
+	input := &dynamodb.UpdateContinuousBackupsInput{
+		TableName: aws.String(tableName),
+		PointInTimeRecoverySpecification: &types.PointInTimeRecoverySpecification{
+			PointInTimeRecoveryEnabled: aws.Bool(enabled),
+		},
+	}
+
+	if _, err := call(ctx, d, "UpdateContinuousBackups", input.TableName, input, d.client.UpdateContinuousBackups); err != nil {
+		return fmt.Errorf("error updating point in time recovery: %w", mapTableErr(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package ddb
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DecodePolymorphic unmarshals a single table design item into the concrete type registered for the value of its typeAttr string attribute.
+// every registry func must return a pointer to a new value, that pointer is what gets returned. a discriminator with no registered type returns ErrUnknownEntityType
+func DecodePolymorphic(item map[string]types.AttributeValue, typeAttr string, registry map[string]func() any) (any, error) {
+	av, ok := item[typeAttr]
+	if !ok {
+		return nil, fmt.Errorf("item has no %s type attribute", typeAttr)
+	}
+
+	s, ok := av.(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, fmt.Errorf("type attribute %s is %T, expected a string", typeAttr, av)
+	}
+
+	newValue, ok := registry[s.Value]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s=%q", ErrUnknownEntityType, typeAttr, s.Value)
+	}
+
+	v := newValue()
+	if err := attributevalue.UnmarshalMap(item, v); err != nil {
+		return nil, fmt.Errorf("error unmarshalling %s item: %w", s.Value, err)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,28 @@
+package ddb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GetOneProjected is a wrapper around GetOne that only returns the provided top level attributes of the item.
+// every attribute is aliased through ExpressionAttributeNames so reserved words like "name" or "status" are safe to use, with no attrs the whole item is returned
+func (d *DynamoDB) GetOneProjected(ctx context.Context, tableName string, key map[string]types.AttributeValue, attrs ...string) (map[string]types.AttributeValue, error) {
+	input := &dynamodb.GetItemInput{
+		TableName: aws.String(tableName),
+		Key:       key,
+	}
+
+	if len(attrs) > 0 {
+		names, aliases := ExprNames(attrs...)
+
+		input.ProjectionExpression = aws.String(strings.Join(aliases, ", "))
+		input.ExpressionAttributeNames = names
+	}
+
+	return d.GetOne(ctx, input)
+}
@@ -0,0 +1,49 @@
+package ddb
+
+import (
+	"context"
+	"math"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"golang.org/x/time/rate"
+)
+
+// WithWriteRateLimit paces PutItem and batch writes so they stay under wcuPerSec write capacity units per second.
+// each request is charged one unit per started 1KB of every item it writes, which is how dynamo bills provisioned writes
+func WithWriteRateLimit(wcuPerSec float64) Option {
+	return func(d *DynamoDB) {
+		burst := max(1, int(math.Ceil(wcuPerSec)))
+		d.writeLimiter = rate.NewLimiter(rate.Limit(wcuPerSec), burst)
+	}
+}
+
+// waitWrite blocks until the write limiter allows the provided capacity units, requests larger than a second's worth are paced over several seconds
+func (d *DynamoDB) waitWrite(ctx context.Context, units int) error {
+	if d.writeLimiter == nil {
+		return nil
+	}
+
+	burst := d.writeLimiter.Burst()
+	for units > 0 {
+		n := min(units, burst)
+		if err := d.writeLimiter.WaitN(ctx, n); err != nil {
+			return err
+		}
+		units -= n
+	}
+
+	return nil
+}
+
+// writeRequestUnits returns the write capacity units a batch of write requests consumes, deletes are charged a single unit
+func writeRequestUnits(writes []types.WriteRequest) int {
+	var units int
+	for _, w := range writes {
+		if w.PutRequest != nil {
+			units += writeUnits(w.PutRequest.Item)
+		} else {
+			units++
+		}
+	}
+	return units
+}
@@ -0,0 +1,27 @@
+package ddb
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// WithReadRegion sends GetItem, Query and Scan calls, and every helper built on them, to a second client in region while writes keep going to the default region, e.g. to read a global table from its nearest replica.
+// replication between global table regions is asynchronous, so a read in region can miss a write made moments before in the default region and ConsistentRead is only consistent within the replica being read.
+// read-modify-write flows should read from the default region. when the wrapper is built from a DynamoAPI that isn't a *dynamodb.Client the option has no effect
+func WithReadRegion(region string) Option {
+	return func(d *DynamoDB) {
+		d.readRegion = region
+	}
+}
+
+// reader returns the client reads are sent to
+func (d *DynamoDB) reader() DynamoAPI {
+	if d.readClient != nil {
+		return d.readClient
+	}
+	return d.client
+}
+
+// readRegionOption overrides the region of the read client
+func (d *DynamoDB) readRegionOption(o *dynamodb.Options) {
+	o.Region = d.readRegion
+}
@@ -0,0 +1,67 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// retryBudgetKey is the context key the retry budget is stored under
+type retryBudgetKey struct{}
+
+// retryBudget is a retry allowance shared by every call made under the same context
+type retryBudget struct {
+	remaining atomic.Int64
+	deadline  time.Time
+}
+
+// WithBudget attaches a retry budget to ctx that every dynamo call made with it, or a context derived from it, draws from, e.g. all the calls of a single HTTP request.
+// at most maxRetries retries are made in total, SDK retries of failed calls and the package's retries of unprocessed batch items alike, and none once deadline has passed, a zero deadline means no deadline.
+// the deadline only stops retries, set a context deadline to bound the calls themselves. once the budget is spent the error that would have been retried is returned wrapped with ErrRetryBudgetExhausted
+func WithBudget(ctx context.Context, maxRetries int, deadline time.Time) context.Context {
+	b := &retryBudget{deadline: deadline}
+	b.remaining.Store(int64(maxRetries))
+	return context.WithValue(ctx, retryBudgetKey{}, b)
+}
+
+// takeRetry reports whether the budget attached to ctx allows one more retry, always true when ctx has no budget
+func takeRetry(ctx context.Context) bool {
+	b, ok := ctx.Value(retryBudgetKey{}).(*retryBudget)
+	if !ok {
+		return true
+	}
+	if !b.deadline.IsZero() && time.Now().After(b.deadline) {
+		return false
+	}
+	return b.remaining.Add(-1) >= 0
+}
+
+// budgetRetryer makes the SDK retryer of the underlying client draw every retry from the budget of the call's context
+type budgetRetryer struct {
+	aws.Retryer
+}
+
+func (r budgetRetryer) GetAttemptToken(ctx context.Context) (func(error) error, error) {
+	if v2, ok := r.Retryer.(aws.RetryerV2); ok {
+		return v2.GetAttemptToken(ctx)
+	}
+	return r.GetInitialToken(), nil
+}
+
+func (r budgetRetryer) GetRetryToken(ctx context.Context, opErr error) (func(error) error, error) {
+	if !takeRetry(ctx) {
+		return nil, fmt.Errorf("%w: %w", ErrRetryBudgetExhausted, opErr)
+	}
+	return r.Retryer.GetRetryToken(ctx, opErr)
+}
+
+// retryBudgetOption wraps the retryer of the client so it honors WithBudget, it must run after any option replacing the retryer
+func retryBudgetOption(o *dynamodb.Options) {
+	if o.Retryer != nil {
+		o.Retryer = budgetRetryer{Retryer: o.Retryer}
+	}
+}
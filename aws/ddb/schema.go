@@ -0,0 +1,110 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// KeySchema describes the primary key a table is expected to have, RangeKey is left empty for tables with only a hash key
+type KeySchema struct {
+	HashKey   string
+	RangeKey  string
+	HashType  types.ScalarAttributeType
+	RangeType types.ScalarAttributeType
+}
+
+// ValidateSchema checks that the table's primary key matches expected, comparing the hash and range key names and types.
+// every difference found is listed in the returned error which wraps ErrSchemaMismatch, a missing table returns ErrTableNotFound
+func (d *DynamoDB) ValidateSchema(ctx context.Context, tableName string, expected KeySchema) error {
+	output, err := d.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		return mapTableErr(err)
+	}
+
+	var actual KeySchema
+	for _, k := range output.Table.KeySchema {
+		switch k.KeyType {
+		case types.KeyTypeHash:
+			actual.HashKey = aws.ToString(k.AttributeName)
+		case types.KeyTypeRange:
+			actual.RangeKey = aws.ToString(k.AttributeName)
+		}
+	}
+	for _, a := range output.Table.AttributeDefinitions {
+		switch aws.ToString(a.AttributeName) {
+		case actual.HashKey:
+			actual.HashType = a.AttributeType
+		case actual.RangeKey:
+			actual.RangeType = a.AttributeType
+		}
+	}
+
+	var diffs []string
+	if actual.HashKey != expected.HashKey {
+		diffs = append(diffs, fmt.Sprintf("hash key is %q, expected %q", actual.HashKey, expected.HashKey))
+	} else if actual.HashType != expected.HashType {
+		diffs = append(diffs, fmt.Sprintf("hash key %s has type %q, expected %q", actual.HashKey, actual.HashType, expected.HashType))
+	}
+	if actual.RangeKey != expected.RangeKey {
+		diffs = append(diffs, fmt.Sprintf("range key is %q, expected %q", actual.RangeKey, expected.RangeKey))
+	} else if actual.RangeKey != "" && actual.RangeType != expected.RangeType {
+		diffs = append(diffs, fmt.Sprintf("range key %s has type %q, expected %q", actual.RangeKey, actual.RangeType, expected.RangeType))
+	}
+
+	if len(diffs) > 0 {
+		return fmt.Errorf("%w: table %s: %s", ErrSchemaMismatch, tableName, strings.Join(diffs, ", "))
+	}
+
+	return nil
+}
+
+// ValidateItemKeys checks that item carries every key attribute of the table with the type its attribute definition declares and a non empty value, catching before the write what dynamo would reject with a ValidationException.
+// the key schema comes from the same hourly cache as KeyAttributes, non key attributes are not checked since dynamo doesn't constrain them. every problem found is listed in the returned error which wraps ErrInvalidItemKey
+func (d *DynamoDB) ValidateItemKeys(ctx context.Context, tableName string, item map[string]types.AttributeValue) error {
+	entry, err := d.tableSchema(ctx, tableName)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, name := range entry.attrs {
+		av, ok := item[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("key attribute %s is missing", name))
+			continue
+		}
+
+		var actual types.ScalarAttributeType
+		var empty bool
+		switch v := av.(type) {
+		case *types.AttributeValueMemberS:
+			actual, empty = types.ScalarAttributeTypeS, v.Value == ""
+		case *types.AttributeValueMemberN:
+			actual = types.ScalarAttributeTypeN
+		case *types.AttributeValueMemberB:
+			actual, empty = types.ScalarAttributeTypeB, len(v.Value) == 0
+		default:
+			problems = append(problems, fmt.Sprintf("key attribute %s is %T, keys must be strings, numbers or binary", name, av))
+			continue
+		}
+
+		if expected := entry.keyTypes[name]; actual != expected {
+			problems = append(problems, fmt.Sprintf("key attribute %s has type %q, expected %q", name, actual, expected))
+		} else if empty {
+			problems = append(problems, fmt.Sprintf("key attribute %s is empty", name))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: table %s: %s", ErrInvalidItemKey, tableName, strings.Join(problems, ", "))
+	}
+
+	return nil
+}
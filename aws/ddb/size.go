@@ -0,0 +1,83 @@
+package ddb
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ItemSize approximates the size in bytes dynamo bills for an item, the sum of every attribute name and value size, following the documented sizing rules:
+//   - strings and binary are their length in bytes, UTF-8 for strings
+//   - numbers take one byte per two significant digits plus one byte
+//   - booleans and nulls take one byte
+//   - sets are the sum of their elements
+//   - lists and maps take 3 bytes plus one byte per element on top of the elements, and map keys count like attribute names
+//
+// writes are billed per started 1KB and strongly consistent reads per started 4KB, the batch write helpers also use it to keep each BatchWriteItem under the 16MB request limit
+func ItemSize(item map[string]types.AttributeValue) int {
+	var size int
+	for name, av := range item {
+		size += len(name) + attributeSize(av)
+	}
+	return size
+}
+
+// attributeSize is the size of a single attribute value, see ItemSize
+func attributeSize(av types.AttributeValue) int {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return numberSize(v.Value)
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberBOOL, *types.AttributeValueMemberNULL:
+		return 1
+	case *types.AttributeValueMemberSS:
+		var size int
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		var size int
+		for _, n := range v.Value {
+			size += numberSize(n)
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		var size int
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := 3
+		for _, e := range v.Value {
+			size += 1 + attributeSize(e)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		size := 3
+		for name, e := range v.Value {
+			size += 1 + len(name) + attributeSize(e)
+		}
+		return size
+	default:
+		return 0
+	}
+}
+
+// numberSize approximates a number as one byte per two significant digits plus one
+func numberSize(n string) int {
+	var digits int
+	for _, c := range n {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	return (digits+1)/2 + 1
+}
+
+// writeUnits returns the write capacity units a put of the item consumes, one per started 1KB
+func writeUnits(item map[string]types.AttributeValue) int {
+	return max(1, (ItemSize(item)+1023)/1024)
+}
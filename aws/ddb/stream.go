@@ -0,0 +1,248 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodbstreams"
+	streamtypes "github.com/aws/aws-sdk-go-v2/service/dynamodbstreams/types"
+	"github.com/jap1998/aws-code-snippets/aws/configuration"
+)
+
+const (
+	// defaultStreamPollInterval is how long a shard with no new records waits before polling again
+	defaultStreamPollInterval = time.Second
+	// defaultShardRefreshInterval is how often the stream is described again to pick up shards created by splits
+	defaultShardRefreshInterval = 30 * time.Second
+)
+
+// StreamReaderOptions configures where a StreamReader starts reading and how it checkpoints, every field is optional
+type StreamReaderOptions struct {
+	// IteratorType is where shards without a checkpoint start reading, defaults to TRIM_HORIZON (oldest available record)
+	IteratorType streamtypes.ShardIteratorType
+	// Resume returns the last checkpointed sequence number of a shard so reading continues after it, an empty string falls back to IteratorType
+	Resume func(ctx context.Context, shardID string) (string, error)
+	// Checkpoint is called with the shard and sequence number of every record once it has been received from the channel, an error stops the reader
+	Checkpoint func(ctx context.Context, shardID, sequenceNumber string) error
+	// PollInterval is how long a shard with no new records waits before polling again, defaults to 1s
+	PollInterval time.Duration
+	// ShardRefreshInterval is how often new shards are discovered, defaults to 30s
+	ShardRefreshInterval time.Duration
+}
+
+// StreamReader reads the change records of a dynamodb stream across all its shards, following shard splits as they happen
+type StreamReader struct {
+	client    *dynamodbstreams.Client
+	streamArn string
+	opts      StreamReaderOptions
+	err       error
+}
+
+// MustNewStreamReader inits a streams client from the default config and returns a StreamReader for the provided stream ARN, if an error occurs it panics.
+func MustNewStreamReader(ctx context.Context, streamArn string, opts StreamReaderOptions) *StreamReader {
+	c := configuration.MustGetConfig(ctx)
+	return NewStreamReader(dynamodbstreams.NewFromConfig(c), streamArn, opts)
+}
+
+// NewStreamReader returns a StreamReader for the provided stream ARN using an already initialized streams client
+func NewStreamReader(client *dynamodbstreams.Client, streamArn string, opts StreamReaderOptions) *StreamReader {
+	if opts.IteratorType == "" {
+		opts.IteratorType = streamtypes.ShardIteratorTypeTrimHorizon
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = defaultStreamPollInterval
+	}
+	if opts.ShardRefreshInterval <= 0 {
+		opts.ShardRefreshInterval = defaultShardRefreshInterval
+	}
+
+	return &StreamReader{
+		client:    client,
+		streamArn: streamArn,
+		opts:      opts,
+	}
+}
+
+// Records starts reading every shard of the stream and returns a channel with their records, records of a shard are delivered in order and a child shard is only read once its parent is done.
+// the channel is closed when ctx is done or reading fails, check Err once it is closed to tell them apart
+func (r *StreamReader) Records(ctx context.Context) <-chan streamtypes.Record {
+	out := make(chan streamtypes.Record)
+
+	go func() {
+		defer close(out)
+
+		readCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		var once sync.Once
+		var firstErr error
+		started := make(map[string]bool)
+		finished := make(map[string]bool)
+
+		fail := func(err error) {
+			// the caller cancelling ctx is how reading is stopped, not a failure
+			if ctx.Err() != nil {
+				return
+			}
+			once.Do(func() {
+				firstErr = err
+				cancel()
+			})
+		}
+
+		for readCtx.Err() == nil {
+			shards, err := r.listShards(readCtx)
+			if err != nil {
+				fail(err)
+				break
+			}
+
+			known := make(map[string]bool, len(shards))
+			for _, s := range shards {
+				known[aws.ToString(s.ShardId)] = true
+			}
+
+			for _, s := range shards {
+				id, parent := aws.ToString(s.ShardId), aws.ToString(s.ParentShardId)
+
+				mu.Lock()
+				ready := !started[id] && (parent == "" || !known[parent] || finished[parent])
+				if ready {
+					started[id] = true
+				}
+				mu.Unlock()
+
+				if !ready {
+					continue
+				}
+
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if err := r.readShard(readCtx, id, out); err != nil {
+						fail(err)
+						return
+					}
+					mu.Lock()
+					finished[id] = true
+					mu.Unlock()
+				}()
+			}
+
+			if err := sleep(readCtx, r.opts.ShardRefreshInterval); err != nil {
+				break
+			}
+		}
+
+		wg.Wait()
+		r.err = firstErr
+	}()
+
+	return out
+}
+
+// Err returns the error that stopped the reader, if any. it is only valid once the channel returned by Records is closed
+func (r *StreamReader) Err() error {
+	return r.err
+}
+
+// listShards describes the stream and returns every shard it currently has
+func (r *StreamReader) listShards(ctx context.Context) ([]streamtypes.Shard, error) {
+	var shards []streamtypes.Shard
+	input := &dynamodbstreams.DescribeStreamInput{StreamArn: aws.String(r.streamArn)}
+
+	for {
+		output, err := r.client.DescribeStream(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error describing stream: %w", err)
+		}
+		shards = append(shards, output.StreamDescription.Shards...)
+		if output.StreamDescription.LastEvaluatedShardId == nil {
+			return shards, nil
+		}
+		input.ExclusiveStartShardId = output.StreamDescription.LastEvaluatedShardId
+	}
+}
+
+// readShard sends every record of the shard to out until the shard is closed or ctx is done, iterators that expire are renewed from the last record read
+func (r *StreamReader) readShard(ctx context.Context, shardID string, out chan<- streamtypes.Record) error {
+	var seq string
+	if r.opts.Resume != nil {
+		s, err := r.opts.Resume(ctx, shardID)
+		if err != nil {
+			return fmt.Errorf("error resuming shard %s: %w", shardID, err)
+		}
+		seq = s
+	}
+
+	iterator, err := r.shardIterator(ctx, shardID, seq)
+	if err != nil {
+		return err
+	}
+
+	for iterator != nil {
+		output, err := r.client.GetRecords(ctx, &dynamodbstreams.GetRecordsInput{ShardIterator: iterator})
+		if err != nil {
+			var expired *streamtypes.ExpiredIteratorException
+			if errors.As(err, &expired) {
+				if iterator, err = r.shardIterator(ctx, shardID, seq); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("error reading shard %s: %w", shardID, err)
+		}
+
+		for _, record := range output.Records {
+			select {
+			case out <- record:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			if record.Dynamodb != nil {
+				seq = aws.ToString(record.Dynamodb.SequenceNumber)
+			}
+			if r.opts.Checkpoint != nil {
+				if err := r.opts.Checkpoint(ctx, shardID, seq); err != nil {
+					return fmt.Errorf("error checkpointing shard %s: %w", shardID, err)
+				}
+			}
+		}
+
+		iterator = output.NextShardIterator
+		if len(output.Records) == 0 && iterator != nil {
+			if err := sleep(ctx, r.opts.PollInterval); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// shardIterator returns an iterator positioned right after seq, or at IteratorType when seq is empty
+func (r *StreamReader) shardIterator(ctx context.Context, shardID, seq string) (*string, error) {
+	input := &dynamodbstreams.GetShardIteratorInput{
+		StreamArn:         aws.String(r.streamArn),
+		ShardId:           aws.String(shardID),
+		ShardIteratorType: r.opts.IteratorType,
+	}
+	if seq != "" {
+		input.ShardIteratorType = streamtypes.ShardIteratorTypeAfterSequenceNumber
+		input.SequenceNumber = aws.String(seq)
+	}
+
+	output, err := r.client.GetShardIterator(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("error getting iterator for shard %s: %w", shardID, err)
+	}
+
+	return output.ShardIterator, nil
+}
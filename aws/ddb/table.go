@@ -0,0 +1,84 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DescribeTable is a wrapper around dynamodb.DescribeTable with an already initialized client
+func (d *DynamoDB) DescribeTable(ctx context.Context, input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	return call(ctx, d, "DescribeTable", input.TableName, input, d.client.DescribeTable)
+}
+
+// Ping checks that dynamo is reachable and the table is ready to serve requests, it returns nil only when the table status is ACTIVE.
+// a missing table returns ErrTableNotFound, no items are read so it is cheap enough for readiness probes
+func (d *DynamoDB) Ping(ctx context.Context, tableName string) error {
+	output, err := d.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(tableName),
+	})
+	if err != nil {
+		return mapTableErr(err)
+	}
+
+	if status := output.Table.TableStatus; status != types.TableStatusActive {
+		return fmt.Errorf("table %s is %s", tableName, status)
+	}
+
+	return nil
+}
+
+// tableIdlePollInterval is how often WaitUntilTableIdle checks on a table that is still updating
+const tableIdlePollInterval = 5 * time.Second
+
+// WaitUntilTableIdle polls DescribeTable until the table is ACTIVE and none of its global secondary indexes is being created, updated or deleted, so the next UpdateTable won't fail with ResourceInUseException.
+// if the table is still busy after timeout, or ctx is done first, ErrTableNotIdle is returned
+func (d *DynamoDB) WaitUntilTableIdle(ctx context.Context, tableName string, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		output, err := d.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+		if err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("%w: table %s: %w", ErrTableNotIdle, tableName, ctx.Err())
+			}
+			return mapTableErr(err)
+		}
+
+		if tableIdle(output.Table) {
+			return nil
+		}
+
+		if err := sleep(ctx, tableIdlePollInterval); err != nil {
+			return fmt.Errorf("%w: table %s: %w", ErrTableNotIdle, tableName, err)
+		}
+	}
+}
+
+// tableIdle reports whether the table and all its global secondary indexes are ACTIVE
+func tableIdle(table *types.TableDescription) bool {
+	if table.TableStatus != types.TableStatusActive {
+		return false
+	}
+	for _, idx := range table.GlobalSecondaryIndexes {
+		if idx.IndexStatus != types.IndexStatusActive {
+			return false
+		}
+	}
+	return true
+}
+
+// mapTableErr wraps a ResourceNotFoundException with ErrTableNotFound and leaves any other error untouched
+func mapTableErr(err error) error {
+	var rnf *types.ResourceNotFoundException
+	if errors.As(err, &rnf) {
+		return fmt.Errorf("%w: %w", ErrTableNotFound, err)
+	}
+	return err
+}
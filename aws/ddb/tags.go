@@ -0,0 +1,93 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// TagTable adds the provided tags to the table, overwriting the value of keys that are already set. tableArn can also be a plain table name
+func (d *DynamoDB) TagTable(ctx context.Context, tableArn string, tags map[string]string) error {
+	arn, err := d.tableArn(ctx, tableArn)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.TagResourceInput{
+		ResourceArn: aws.String(arn),
+		Tags:        make([]types.Tag, 0, len(tags)),
+	}
+	for k, v := range tags {
+		input.Tags = append(input.Tags, types.Tag{Key: aws.String(k), Value: aws.String(v)})
+	}
+
+	if _, err := call(ctx, d, "TagResource", nil, input, d.client.TagResource); err != nil {
+		return fmt.Errorf("error tagging table: %w", err)
+	}
+
+	return nil
+}
+
+// UntagTable removes the tags with the provided keys from the table. tableArn can also be a plain table name
+func (d *DynamoDB) UntagTable(ctx context.Context, tableArn string, keys []string) error {
+	arn, err := d.tableArn(ctx, tableArn)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.UntagResourceInput{
+		ResourceArn: aws.String(arn),
+		TagKeys:     keys,
+	}
+
+	if _, err := call(ctx, d, "UntagResource", nil, input, d.client.UntagResource); err != nil {
+		return fmt.Errorf("error untagging table: %w", err)
+	}
+
+	return nil
+}
+
+// ListTableTags returns every tag set on the table as a map of key to value. tableArn can also be a plain table name
+func (d *DynamoDB) ListTableTags(ctx context.Context, tableArn string) (map[string]string, error) {
+	arn, err := d.tableArn(ctx, tableArn)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string)
+	input := &dynamodb.ListTagsOfResourceInput{ResourceArn: aws.String(arn)}
+
+	for {
+		output, err := call(ctx, d, "ListTagsOfResource", nil, input, d.client.ListTagsOfResource)
+		if err != nil {
+			return nil, fmt.Errorf("error listing table tags: %w", err)
+		}
+		for _, t := range output.Tags {
+			tags[aws.ToString(t.Key)] = aws.ToString(t.Value)
+		}
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	return tags, nil
+}
+
+// tableArn returns nameOrArn as is when it already is an ARN, otherwise it resolves the table's ARN through DescribeTable
+func (d *DynamoDB) tableArn(ctx context.Context, nameOrArn string) (string, error) {
+	if strings.HasPrefix(nameOrArn, "arn:") {
+		return nameOrArn, nil
+	}
+
+	output, err := d.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(nameOrArn)})
+	if err != nil {
+		return "", mapTableErr(err)
+	}
+
+	return aws.ToString(output.Table.TableArn), nil
+}
@@ -0,0 +1,45 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// UpdateThroughput sets the table's provisioned read and write capacity units, when wait is set it blocks until the table is ACTIVE again.
+// tables in PAY_PER_REQUEST mode can't take provisioned throughput and return ErrPayPerRequest without being updated
+func (d *DynamoDB) UpdateThroughput(ctx context.Context, tableName string, read, write int64, wait bool) error {
+	table, err := d.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)})
+	if err != nil {
+		return mapTableErr(err)
+	}
+
+	if billingMode(table.Table) == types.BillingModePayPerRequest {
+		return fmt.Errorf("%w: table %s", ErrPayPerRequest, tableName)
+	}
+
+	input := &dynamodb.UpdateTableInput{
+		TableName: aws.String(tableName),
+		ProvisionedThroughput: &types.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(read),
+			WriteCapacityUnits: aws.Int64(write),
+		},
+	}
+	if _, err := call(ctx, d, "UpdateTable", input.TableName, input, d.client.UpdateTable); err != nil {
+		return fmt.Errorf("error updating throughput: %w", mapTableErr(err))
+	}
+
+	if !wait {
+		return nil
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(d.client)
+	if err := waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(tableName)}, maxTableCreateWait); err != nil {
+		return fmt.Errorf("error waiting for table %s throughput update: %w", tableName, err)
+	}
+
+	return nil
+}
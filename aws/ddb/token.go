@@ -0,0 +1,56 @@
+package ddb
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+)
+
+// WithTokenKey encrypts and authenticates pagination tokens with AES-GCM so clients can neither read the key inside a token nor forge one to reach other partitions.
+// any key length is accepted, it is stretched to 256 bits with SHA-256. tokens that fail authentication return ErrInvalidToken
+func WithTokenKey(key []byte) Option {
+	return func(d *DynamoDB) {
+		sum := sha256.Sum256(key)
+
+		block, err := aes.NewCipher(sum[:])
+		if err != nil {
+			// a 32 byte key is always valid for aes
+			panic(err)
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			panic(err)
+		}
+
+		d.tokenAEAD = aead
+	}
+}
+
+// sealToken encrypts b prefixing the random nonce to the ciphertext
+func sealToken(aead cipher.AEAD, b []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(b)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating token nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, b, nil), nil
+}
+
+// openToken is the inverse of sealToken
+func openToken(aead cipher.AEAD, b []byte) ([]byte, error) {
+	if len(b) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: too short", ErrInvalidToken)
+	}
+
+	nonce, sealed := b[:aead.NonceSize()], b[aead.NonceSize():]
+
+	plain, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	return plain, nil
+}
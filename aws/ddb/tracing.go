@@ -0,0 +1,88 @@
+package ddb
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "github.com/jap1998/aws-code-snippets/aws/ddb"
+
+// noopSpan is handed out when no tracer is configured so call sites don't need nil checks
+var noopSpan trace.Span = noop.Span{}
+
+// WithTracer starts a span for every dynamo call made through the wrapper with the operation, table and consumed capacity as attributes.
+// paginating helpers get a parent span covering the whole operation with a child span per page, without this option no spans are created
+func WithTracer(tp trace.TracerProvider) Option {
+	return func(d *DynamoDB) {
+		d.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// startSpan starts a client span for the operation, or returns a no-op span when tracing is disabled
+func (d *DynamoDB) startSpan(ctx context.Context, operation string, tableName *string) (context.Context, trace.Span) {
+	if d.tracer == nil {
+		return ctx, noopSpan
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", "dynamodb"),
+		attribute.String("db.operation", operation),
+	}
+	if tableName != nil {
+		attrs = append(attrs, attribute.String("db.table", aws.ToString(tableName)))
+	}
+
+	return d.tracer.Start(ctx, "DynamoDB."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// endSpan records err on the span, if any, and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// consumedCapacity sums the capacity units reported on an operation output, zero when the caller didn't ask for ReturnConsumedCapacity
+func consumedCapacity(output any) float64 {
+	var ccs []types.ConsumedCapacity
+
+	switch o := output.(type) {
+	case *dynamodb.GetItemOutput:
+		ccs = capacityOf(o.ConsumedCapacity)
+	case *dynamodb.PutItemOutput:
+		ccs = capacityOf(o.ConsumedCapacity)
+	case *dynamodb.UpdateItemOutput:
+		ccs = capacityOf(o.ConsumedCapacity)
+	case *dynamodb.DeleteItemOutput:
+		ccs = capacityOf(o.ConsumedCapacity)
+	case *dynamodb.QueryOutput:
+		ccs = capacityOf(o.ConsumedCapacity)
+	case *dynamodb.ScanOutput:
+		ccs = capacityOf(o.ConsumedCapacity)
+	case *dynamodb.BatchWriteItemOutput:
+		ccs = o.ConsumedCapacity
+	}
+
+	var total float64
+	for _, cc := range ccs {
+		total += aws.ToFloat64(cc.CapacityUnits)
+	}
+
+	return total
+}
+
+func capacityOf(cc *types.ConsumedCapacity) []types.ConsumedCapacity {
+	if cc == nil {
+		return nil
+	}
+	return []types.ConsumedCapacity{*cc}
+}
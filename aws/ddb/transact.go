@@ -0,0 +1,158 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxTransactItems is the maximum number of actions dynamo accepts in a single transaction
+const maxTransactItems = 100
+
+// TransactChunkError is returned by TransactWriteBatched when one of its transactions fails, Applied transactions were fully committed and the rest were never sent
+type TransactChunkError struct {
+	Applied int
+	Total   int
+	Err     error
+}
+
+func (e *TransactChunkError) Error() string {
+	return fmt.Sprintf("error writing transaction %d of %d, %d applied: %v", e.Applied+1, e.Total, e.Applied, e.Err)
+}
+
+func (e *TransactChunkError) Unwrap() error {
+	return e.Err
+}
+
+// TransactWriteItems is a wrapper around dynamodb.TransactWriteItems with an already initialized client
+func (d *DynamoDB) TransactWriteItems(ctx context.Context, input *dynamodb.TransactWriteItemsInput) (*dynamodb.TransactWriteItemsOutput, error) {
+	if d.recordDryRun("TransactWriteItems", nil, len(input.TransactItems), input) {
+		return &dynamodb.TransactWriteItemsOutput{}, nil
+	}
+	return call(ctx, d, "TransactWriteItems", nil, input, d.client.TransactWriteItems)
+}
+
+// TransactWriteBatched splits items into transactions of at most 100 actions and executes them one after the other.
+// atomicity only holds within each transaction, not across them: when one fails the ones before it stay applied, the error is a *TransactChunkError telling how many were
+func (d *DynamoDB) TransactWriteBatched(ctx context.Context, items []types.TransactWriteItem) error {
+	total := (len(items) + maxTransactItems - 1) / maxTransactItems
+
+	for i := 0; i < total; i++ {
+		start := i * maxTransactItems
+		end := min(start+maxTransactItems, len(items))
+
+		if _, err := d.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{
+			TransactItems: items[start:end],
+		}); err != nil {
+			return &TransactChunkError{Applied: i, Total: total, Err: err}
+		}
+	}
+
+	return nil
+}
+
+// TxBuilder accumulates the actions of a single transaction, build one with NewTx and chain Put, Delete, Update and ConditionCheck before calling Execute.
+// an error in any step, like a value that can't be marshalled or an invalid expression, is kept and returned by Execute so the chain needs no checks in between
+type TxBuilder struct {
+	d     *DynamoDB
+	items []types.TransactWriteItem
+	err   error
+}
+
+// NewTx returns an empty transaction builder executed through this wrapper
+func (d *DynamoDB) NewTx() *TxBuilder {
+	return &TxBuilder{d: d}
+}
+
+// Put adds a put of v, marshalled with the wrapper's encoder options and with the WithDerivedKeys attributes added, to the transaction
+func (b *TxBuilder) Put(tableName string, v any) *TxBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	item, err := b.d.marshalMap(v)
+	if err != nil {
+		b.err = fmt.Errorf("error marshalling action %d: %w", len(b.items), err)
+		return b
+	}
+
+	b.items = append(b.items, types.TransactWriteItem{Put: &types.Put{
+		TableName: aws.String(tableName),
+		Item:      b.d.withDerivedKeys(item),
+	}})
+	return b
+}
+
+// Delete adds a delete of the item with the provided key to the transaction
+func (b *TxBuilder) Delete(tableName string, key map[string]types.AttributeValue) *TxBuilder {
+	b.items = append(b.items, types.TransactWriteItem{Delete: &types.Delete{
+		TableName: aws.String(tableName),
+		Key:       key,
+	}})
+	return b
+}
+
+// Update adds an update of the item with the provided key to the transaction
+func (b *TxBuilder) Update(tableName string, key map[string]types.AttributeValue, update expression.UpdateBuilder) *TxBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	expr, err := expression.NewBuilder().WithUpdate(update).Build()
+	if err != nil {
+		b.err = fmt.Errorf("error building update of action %d: %w", len(b.items), err)
+		return b
+	}
+
+	b.items = append(b.items, types.TransactWriteItem{Update: &types.Update{
+		TableName:                 aws.String(tableName),
+		Key:                       key,
+		UpdateExpression:          expr.Update(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}})
+	return b
+}
+
+// ConditionCheck adds a check that the condition holds on the item with the provided key, the whole transaction is cancelled when it doesn't
+func (b *TxBuilder) ConditionCheck(tableName string, key map[string]types.AttributeValue, condition expression.ConditionBuilder) *TxBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	expr, err := expression.NewBuilder().WithCondition(condition).Build()
+	if err != nil {
+		b.err = fmt.Errorf("error building condition of action %d: %w", len(b.items), err)
+		return b
+	}
+
+	b.items = append(b.items, types.TransactWriteItem{ConditionCheck: &types.ConditionCheck{
+		TableName:                 aws.String(tableName),
+		Key:                       key,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	}})
+	return b
+}
+
+// Execute runs the accumulated actions as a single TransactWriteItems call, it returns the first error of the chain or an error when there are no actions or more than 100
+func (b *TxBuilder) Execute(ctx context.Context) error {
+	if b.err != nil {
+		return b.err
+	}
+	if len(b.items) == 0 {
+		return errors.New("transaction has no actions")
+	}
+	if len(b.items) > maxTransactItems {
+		return fmt.Errorf("transaction has %d actions, at most %d are allowed", len(b.items), maxTransactItems)
+	}
+
+	_, err := b.d.TransactWriteItems(ctx, &dynamodb.TransactWriteItemsInput{TransactItems: b.items})
+	return err
+}
@@ -0,0 +1,47 @@
+package ddb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Truncate deletes every item of the table without dropping it and returns how many items were removed, the key attributes are discovered through KeyAttributes.
+// the scan only projects the key attributes and deletes go through BatchWrite, running it on an empty table is a no-op that returns 0
+func (d *DynamoDB) Truncate(ctx context.Context, tableName string) (_ int, err error) {
+	ctx, span := d.startSpan(ctx, "Truncate", aws.String(tableName))
+	defer func() { endSpan(span, err) }()
+
+	keyAttrs, err := d.keySchema(ctx, tableName)
+	if err != nil {
+		return 0, err
+	}
+
+	names, aliases := ExprNames(keyAttrs...)
+	w := d.newBatchWriter(tableName)
+
+	err = d.ScanPages(ctx, &dynamodb.ScanInput{
+		TableName:                aws.String(tableName),
+		ProjectionExpression:     aws.String(strings.Join(aliases, ", ")),
+		ExpressionAttributeNames: names,
+	}, func(page []map[string]types.AttributeValue) error {
+		for _, key := range page {
+			if err := w.add(ctx, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: key}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return w.written, err
+	}
+
+	if err := w.flush(ctx); err != nil {
+		return w.written, err
+	}
+
+	return w.written, nil
+}
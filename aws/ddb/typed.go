@@ -0,0 +1,125 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ScanAllAs is a wrapper around ScanAll that unmarshals every scanned item into T
+func ScanAllAs[T any](ctx context.Context, d *DynamoDB, input *dynamodb.ScanInput) ([]T, error) {
+	items, err := d.ScanAll(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalItems[T](d, items)
+}
+
+// QueryAllAs is a wrapper around QueryAll that unmarshals every item returned by the query into T
+func QueryAllAs[T any](ctx context.Context, d *DynamoDB, input *dynamodb.QueryInput) ([]T, error) {
+	items, err := d.QueryAll(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalItems[T](d, items)
+}
+
+// QueryOneAs is a wrapper around QueryOne that unmarshals the matched item into T
+func QueryOneAs[T any](ctx context.Context, d *DynamoDB, input *dynamodb.QueryInput) (T, error) {
+	var out T
+
+	item, err := d.QueryOne(ctx, input)
+	if err != nil {
+		return out, err
+	}
+
+	if err := d.unmarshalMap(item, &out); err != nil {
+		return out, fmt.Errorf("error unmarshalling item: %w", err)
+	}
+
+	return out, nil
+}
+
+// unmarshalItems unmarshals each item into T, reporting the index of the first item that fails
+func unmarshalItems[T any](d *DynamoDB, items []map[string]types.AttributeValue) ([]T, error) {
+	out := make([]T, len(items))
+	for i, item := range items {
+		if err := d.unmarshalMap(item, &out[i]); err != nil {
+			return nil, fmt.Errorf("error unmarshalling item %d: %w", i, err)
+		}
+	}
+
+	return out, nil
+}
+
+// QueryWithPaginationAs is a wrapper around QueryWithPagination that unmarshals the page items into T
+func QueryWithPaginationAs[T any](ctx context.Context, d *DynamoDB, input *PaginationOps) (*PaginatedResultsOf[T], error) {
+	results, err := d.QueryWithPagination(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := unmarshalItems[T](d, results.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaginatedResultsOf[T]{
+		Items:      items,
+		Skip:       results.Skip,
+		Limit:      results.Limit,
+		Count:      results.Count,
+		TotalPages: results.TotalPages,
+		Page:       results.Page,
+	}, nil
+}
+
+// UpdateReturning is a wrapper around UpdateItem that returns the item as it is after the update unmarshalled into T.
+// ReturnValues is forced to ALL_NEW unless the caller already asked for ALL_NEW or UPDATED_NEW, in the latter case only the updated attributes are set on T
+func UpdateReturning[T any](ctx context.Context, d *DynamoDB, input *dynamodb.UpdateItemInput) (T, error) {
+	var out T
+
+	in := *input
+	if in.ReturnValues != types.ReturnValueAllNew && in.ReturnValues != types.ReturnValueUpdatedNew {
+		in.ReturnValues = types.ReturnValueAllNew
+	}
+
+	output, err := d.UpdateItem(ctx, &in)
+	if err != nil {
+		return out, err
+	}
+
+	if err := d.unmarshalMap(output.Attributes, &out); err != nil {
+		return out, fmt.Errorf("error unmarshalling item: %w", err)
+	}
+
+	return out, nil
+}
+
+// DeleteReturning is a wrapper around DeleteItem that returns the deleted item unmarshalled into T, deleted is false and T its zero value when there was no item to delete.
+// ReturnValues is forced to ALL_OLD, the only value DeleteItem supports besides NONE, the input itself is left untouched
+func DeleteReturning[T any](ctx context.Context, d *DynamoDB, input *dynamodb.DeleteItemInput) (_ T, deleted bool, err error) {
+	var out T
+
+	in := *input
+	in.ReturnValues = types.ReturnValueAllOld
+
+	output, err := d.DeleteItem(ctx, &in)
+	if err != nil {
+		return out, false, err
+	}
+
+	if len(output.Attributes) == 0 {
+		return out, false, nil
+	}
+
+	if err := d.unmarshalMap(output.Attributes, &out); err != nil {
+		return out, true, fmt.Errorf("error unmarshalling item: %w", err)
+	}
+
+	return out, true, nil
+}
@@ -0,0 +1,107 @@
+package ddb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// RemoveAttributes drops the provided attributes from the item with a single REMOVE update, names are aliased so reserved words are safe.
+// no request is sent when attrs is empty
+func (d *DynamoDB) RemoveAttributes(ctx context.Context, tableName string, key map[string]types.AttributeValue, attrs ...string) error {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	names, aliases := ExprNames(attrs...)
+
+	_, err := d.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String(tableName),
+		Key:                      key,
+		UpdateExpression:         aws.String("REMOVE " + strings.Join(aliases, ", ")),
+		ExpressionAttributeNames: names,
+	})
+
+	return err
+}
+
+// AddToStringSet adds values to the string set attr with an ADD update, creating the set when the attribute doesn't exist yet.
+// no request is sent when values is empty
+func (d *DynamoDB) AddToStringSet(ctx context.Context, tableName string, key map[string]types.AttributeValue, attr string, values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return d.updateSet(ctx, tableName, key, "ADD", attr, &types.AttributeValueMemberSS{Value: values})
+}
+
+// RemoveFromStringSet deletes values from the string set attr with a DELETE update, removing the last element removes the attribute.
+// no request is sent when values is empty
+func (d *DynamoDB) RemoveFromStringSet(ctx context.Context, tableName string, key map[string]types.AttributeValue, attr string, values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return d.updateSet(ctx, tableName, key, "DELETE", attr, &types.AttributeValueMemberSS{Value: values})
+}
+
+// AddToNumberSet is like AddToStringSet for a number set, values are passed as strings to keep dynamo's arbitrary precision
+func (d *DynamoDB) AddToNumberSet(ctx context.Context, tableName string, key map[string]types.AttributeValue, attr string, values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return d.updateSet(ctx, tableName, key, "ADD", attr, &types.AttributeValueMemberNS{Value: values})
+}
+
+// RemoveFromNumberSet is like RemoveFromStringSet for a number set, values are passed as strings to keep dynamo's arbitrary precision
+func (d *DynamoDB) RemoveFromNumberSet(ctx context.Context, tableName string, key map[string]types.AttributeValue, attr string, values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return d.updateSet(ctx, tableName, key, "DELETE", attr, &types.AttributeValueMemberNS{Value: values})
+}
+
+// updateSet issues an ADD or DELETE update of set against attr
+func (d *DynamoDB) updateSet(ctx context.Context, tableName string, key map[string]types.AttributeValue, action, attr string, set types.AttributeValue) error {
+	_, err := d.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                 aws.String(tableName),
+		Key:                       key,
+		UpdateExpression:          aws.String(action + " #a :v"),
+		ExpressionAttributeNames:  map[string]string{"#a": attr},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":v": set},
+	})
+
+	return err
+}
+
+// AppendToList appends values to the end of the list attr, creating the list when the attribute doesn't exist yet.
+// no request is sent when values is empty
+func (d *DynamoDB) AppendToList(ctx context.Context, tableName string, key map[string]types.AttributeValue, attr string, values []types.AttributeValue) error {
+	return d.updateList(ctx, tableName, key, attr, values, "SET #a = list_append(if_not_exists(#a, :empty), :vals)")
+}
+
+// PrependToList is like AppendToList but inserts values at the start of the list, keeping their order
+func (d *DynamoDB) PrependToList(ctx context.Context, tableName string, key map[string]types.AttributeValue, attr string, values []types.AttributeValue) error {
+	return d.updateList(ctx, tableName, key, attr, values, "SET #a = list_append(:vals, if_not_exists(#a, :empty))")
+}
+
+// updateList issues a list_append update where :empty stands for the missing list and :vals for the values being added
+func (d *DynamoDB) updateList(ctx context.Context, tableName string, key map[string]types.AttributeValue, attr string, values []types.AttributeValue, updateExpression string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	_, err := d.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:                aws.String(tableName),
+		Key:                      key,
+		UpdateExpression:         aws.String(updateExpression),
+		ExpressionAttributeNames: map[string]string{"#a": attr},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":empty": &types.AttributeValueMemberL{Value: []types.AttributeValue{}},
+			":vals":  &types.AttributeValueMemberL{Value: values},
+		},
+	})
+
+	return err
+}
@@ -0,0 +1,85 @@
+package ddb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// UpdateByQuery applies update to every item matched by queryInput and returns how many items were updated, keyAttrs must name the table's key attributes.
+// the query only projects the keys and is paged to the end while a pool of workers issues an UpdateItem per item, each conditioned on the item still existing so one deleted in the meantime is skipped rather than recreated.
+// workers <= 0 uses the wrapper's WithMaxConcurrency, 4 by default, and anything above 16 is capped.
+// the first failure cancels the remaining updates and its error is returned along with the count of the updates that already succeeded, queryInput is not modified
+func (d *DynamoDB) UpdateByQuery(ctx context.Context, queryInput *dynamodb.QueryInput, update expression.UpdateBuilder, keyAttrs []string, workers int) (_ int, err error) {
+	ctx, span := d.startSpan(ctx, "UpdateByQuery", queryInput.TableName)
+	defer func() { endSpan(span, err) }()
+
+	if len(keyAttrs) == 0 {
+		return 0, errors.New("no key attributes provided")
+	}
+
+	expr, err := expression.NewBuilder().
+		WithUpdate(update).
+		WithCondition(expression.AttributeExists(expression.NameNoDotSplit(keyAttrs[0]))).
+		Build()
+	if err != nil {
+		return 0, fmt.Errorf("error building update expression: %w", err)
+	}
+
+	in := *queryInput
+	in.ExpressionAttributeNames = maps.Clone(queryInput.ExpressionAttributeNames)
+	if in.ExpressionAttributeNames == nil {
+		in.ExpressionAttributeNames = make(map[string]string, len(keyAttrs))
+	}
+	aliases := make([]string, len(keyAttrs))
+	for i, k := range keyAttrs {
+		aliases[i] = fmt.Sprintf("#key%d", i)
+		in.ExpressionAttributeNames[aliases[i]] = k
+	}
+	in.ProjectionExpression = aws.String(strings.Join(aliases, ", "))
+	in.Select = types.SelectSpecificAttributes
+
+	var updated atomic.Int64
+
+	err = feedConcurrent(ctx, d.workers(workers), func(ctx context.Context, send func(map[string]types.AttributeValue) bool) error {
+		err := d.QueryPages(ctx, &in, func(page []map[string]types.AttributeValue) error {
+			for _, item := range page {
+				if !send(item) {
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error querying items: %w", err)
+		}
+		return nil
+	}, func(ctx context.Context, key map[string]types.AttributeValue) error {
+		_, err := d.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:                 queryInput.TableName,
+			Key:                       key,
+			UpdateExpression:          expr.Update(),
+			ConditionExpression:       expr.Condition(),
+			ExpressionAttributeNames:  expr.Names(),
+			ExpressionAttributeValues: expr.Values(),
+		})
+		if errors.Is(mapConditionErr(err), ErrConditionFailed) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error updating item %s: %w", keyString(key), err)
+		}
+		updated.Add(1)
+		return nil
+	})
+
+	return int(updated.Load()), err
+}
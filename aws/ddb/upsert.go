@@ -0,0 +1,72 @@
+package ddb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// UpsertMany merges every item into the table, setting its non key attributes on the existing item and creating it when it doesn't exist, attributes missing from an item are left untouched.
+// BatchWriteItem can only replace whole items so each item is its own UpdateItem, issued by a pool of workers, one request per item instead of one per 25.
+// an UpdateItem is billed on the larger of the item before and after it, so merging a few attributes into a large existing item costs the write units of the whole item, not of the record sent.
+// workers <= 0 uses the wrapper's WithMaxConcurrency, 4 by default, and anything above 16 is capped. it stops at the first update that fails and returns its error
+func (d *DynamoDB) UpsertMany(ctx context.Context, tableName string, items []map[string]types.AttributeValue, keyAttrs []string, workers int) (err error) {
+	ctx, span := d.startSpan(ctx, "UpsertMany", aws.String(tableName))
+	defer func() { endSpan(span, err) }()
+
+	return forEachConcurrent(ctx, d.workers(workers), len(items), func(ctx context.Context, i int) error {
+		if _, err := d.UpdateItem(ctx, upsertInput(tableName, items[i], keyAttrs)); err != nil {
+			return fmt.Errorf("error upserting item %d: %w", i, err)
+		}
+		return nil
+	})
+}
+
+// upsertInput builds an UpdateItem that SETs every non key attribute of item
+func upsertInput(tableName string, item map[string]types.AttributeValue, keyAttrs []string) *dynamodb.UpdateItemInput {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(tableName),
+		Key:       make(map[string]types.AttributeValue, len(keyAttrs)),
+	}
+
+	isKey := make(map[string]bool, len(keyAttrs))
+	for _, k := range keyAttrs {
+		isKey[k] = true
+		input.Key[k] = item[k]
+	}
+
+	var attrs []string
+	for name := range item {
+		if !isKey[name] {
+			attrs = append(attrs, name)
+		}
+	}
+	if len(attrs) == 0 {
+		return input
+	}
+	sort.Strings(attrs)
+
+	values := make([]types.AttributeValue, len(attrs))
+	for i, name := range attrs {
+		values[i] = item[name]
+	}
+
+	names, aliases := ExprNames(attrs...)
+	vals, placeholders := ExprValues(values...)
+
+	sets := make([]string, len(attrs))
+	for i := range attrs {
+		sets[i] = aliases[i] + " = " + placeholders[i]
+	}
+
+	input.UpdateExpression = aws.String("SET " + strings.Join(sets, ", "))
+	input.ExpressionAttributeNames = names
+	input.ExpressionAttributeValues = vals
+
+	return input
+}
@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 	"github.com/jap1998/aws-code-snippets/aws/ddb"
@@ -59,14 +58,7 @@ func main() {
 	}
 
 	// this should error
-	key, err := attributevalue.MarshalMap(map[string]string{
-		"primaryKey": "no-exist",
-		"sortKey":    "no-exist",
-	})
-
-	if err != nil {
-		fmt.Println("Error marshalling key", err)
-	}
+	key := ddb.Key("primaryKey", "no-exist", "sortKey", "no-exist")
 
 	_, err = dy.GetOne(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(os.Getenv("TABLE_NAME")),